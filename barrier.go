@@ -0,0 +1,58 @@
+package lockstep
+
+import "sync"
+
+// Barrier blocks a fixed number of goroutines until all of them have arrived.
+// Create one using [LockStep.Barrier].
+type Barrier struct {
+	l   *LockStep
+	tag string
+	n   int
+
+	mu      sync.Mutex
+	arrived int
+}
+
+// Barrier creates a Barrier for n goroutines. The barrier is coordinated using
+// the message tag+"-arrive", which must not be used for anything else.
+//
+//	b := ls.Barrier("start", 3)
+//	for i := 0; i < 3; i++ {
+//		go func() {
+//			b.Arrive()
+//			// All 3 goroutines get here at about the same time.
+//		}()
+//	}
+func (l *LockStep) Barrier(tag string, n int) *Barrier {
+	return &Barrier{
+		l:   l,
+		tag: tag,
+		n:   n,
+	}
+}
+
+// Arrive blocks until all n goroutines have called Arrive, and then releases
+// all of them. A Barrier can only be used once: calling Arrive more than n
+// times fails.
+func (b *Barrier) Arrive() {
+	b.l.t.Helper()
+
+	b.mu.Lock()
+	b.arrived++
+	arrived := b.arrived
+	b.mu.Unlock()
+
+	m := b.tag + "-arrive"
+	switch {
+	case arrived > b.n:
+		b.l.fail(newError(
+			"arrive", b.tag, "too many arrivals",
+			"Barrier %v: too many arrivals (expected %v)", b.tag, b.n))
+	case arrived < b.n:
+		// Block until the last goroutine arrives.
+		b.l.Emit(m)
+	default:
+		// Release all the other goroutines.
+		b.l.WaitN(m, b.n-1)
+	}
+}
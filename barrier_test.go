@@ -0,0 +1,58 @@
+package lockstep_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestBarrier(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	const n = 5
+	b := ls.Barrier("start", n)
+
+	var arrived atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			time.Sleep(time.Duration(i) * 20 * time.Millisecond)
+			arrived.Add(1)
+			b.Arrive()
+			if a := arrived.Load(); a != n {
+				t.Errorf("Released after %v arrivals", a)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestBarrier_TooManyArrivals(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+	b := ls.Barrier("start", 1)
+
+	b.Arrive()
+	expectFail(t, func() {
+		b.Arrive()
+	})
+}
+
+func TestBarrier_Timeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+	b := ls.Barrier("start", 2)
+
+	expectFail(t, func() {
+		b.Arrive()
+	})
+}
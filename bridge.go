@@ -0,0 +1,124 @@
+package lockstep
+
+import "context"
+
+// WrapChan starts a goroutine that calls Emit(m) once ch is closed or
+// receives a value. It bridges code that signals completion through a channel,
+// such as ctx.Done(), with LockStep. The goroutine stops without emitting when
+// the test completes.
+func (l *LockStep) WrapChan(m string, ch <-chan struct{}) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	go func() {
+		select {
+		case <-ch:
+		case <-l.ctx.Done():
+			return
+		}
+		if err := l.emit(l.ctx, m, nil, l.opTimeout(m)); err != nil && l.ctx.Err() == nil {
+			l.fail(err)
+		}
+	}()
+}
+
+// WrapContext calls Emit(m) once ctx is done, e.g. to model the cancellation
+// of a request:
+//
+//	ls.WrapContext(req.Context(), "request-cancelled")
+//
+// Like WrapChan, it emits from a separate goroutine, which stops without
+// emitting when the test completes.
+func (l *LockStep) WrapContext(ctx context.Context, m string) {
+	l.t.Helper()
+
+	l.WrapChan(m, ctx.Done())
+}
+
+// AsChan returns a channel that is closed once an Emit operation for m is
+// processed, so that it can be used in select statements:
+//
+//	select {
+//	case <-ls.AsChan("ready"):
+//		// ...
+//	case <-ctx.Done():
+//		// ...
+//	}
+//
+// AsChan starts a Wait operation for m in a separate goroutine. A timeout
+// fails the test as usual, unless the test has already completed.
+func (l *LockStep) AsChan(m string) <-chan struct{} {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	ch := make(chan struct{}, 1)
+	go func() {
+		if err := l.wait(l.ctx, []string{m}, l.opTimeout(m)); err != nil {
+			if l.ctx.Err() == nil {
+				l.fail(err)
+			}
+			return
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// AsContext returns a context that is cancelled once Emit operations for all
+// the messages in ms have been processed. Calling the returned cancel function
+// cancels the context early and abandons the Wait operation. If the Wait
+// operation fails, the failure is reported as usual and the context is
+// cancelled with the failure as its cause.
+//
+//	ctx, cancel := ls.AsContext("db-ready", "cache-ready")
+//	defer cancel()
+//	srv.Start(ctx)
+func (l *LockStep) AsContext(ms ...string) (context.Context, context.CancelFunc) {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	ctx, cancel := context.WithCancelCause(l.ctx)
+	go func() {
+		if err := l.wait(ctx, ms, l.opTimeout(ms...)); err != nil && ctx.Err() == nil {
+			l.fail(err)
+			cancel(err)
+			return
+		}
+		cancel(nil)
+	}()
+	return ctx, func() { cancel(nil) }
+}
+
+// Bridge forwards Emit operations for m from src to dst: each time an Emit
+// operation for m starts on src, Emit(m) is also called on dst, from a
+// separate goroutine. Multiple bridges can be registered for the same message,
+// and all of them fire. A failure of the forwarded Emit operation is reported
+// by dst.
+func Bridge(src, dst *LockStep, m string) {
+	src.mu.Lock()
+	defer src.mu.Unlock()
+
+	if src.bridges == nil {
+		src.bridges = make(map[string][]bridge)
+	}
+	sm := src.qualify(m)
+	src.bridges[sm] = append(src.bridges[sm], bridge{dst: dst, m: dst.qualify(m)})
+}
+
+// bridge is a destination of Bridge: Emit operations are forwarded to dst as
+// Emit operations for m.
+type bridge struct {
+	dst *LockStep
+	m   string
+}
+
+// forward emits m on behalf of a bridged instance.
+func (l *LockStep) forward(m string) {
+	l.logf("Forwarded %v", m)
+	if err := l.emit(l.ctx, m, nil, l.opTimeout(m)); err != nil && l.ctx.Err() == nil {
+		l.fail(err)
+	}
+}
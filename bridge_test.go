@@ -0,0 +1,118 @@
+package lockstep_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_WrapChan(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	closed := make(chan struct{})
+	ls.WrapChan("closed", closed)
+	close(closed)
+	ls.Wait("closed")
+
+	sent := make(chan struct{})
+	ls.WrapChan("sent", sent)
+	go func() { sent <- struct{}{} }()
+	ls.Wait("sent")
+
+	ls.WrapChan("never", make(chan struct{}))
+	expectFail(t, func() {
+		ls.Wait("never")
+	})
+}
+
+func TestLockStep_AsChan(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	ready := ls.AsChan("ready")
+	select {
+	case <-ready:
+		t.Fatalf("Channel closed before emit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ls.Emit("ready")
+	select {
+	case <-ready:
+	case <-time.After(time.Second):
+		t.Fatalf("Channel not closed after emit")
+	}
+}
+
+func TestLockStep_AsContext(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	ctx, cancel := ls.AsContext("a", "b")
+	defer cancel()
+
+	ls.Emit("a")
+	select {
+	case <-ctx.Done():
+		t.Fatalf("Context done before all emits")
+	case <-time.After(20 * time.Millisecond):
+	}
+	ls.Emit("b")
+	<-ctx.Done()
+
+	ctx, cancel = ls.AsContext("c")
+	cancel()
+	<-ctx.Done()
+	if err := ls.Drain(time.Second); err != nil {
+		t.Fatalf("Wait not abandoned: %v", err)
+	}
+}
+
+func TestLockStep_WrapContext(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ls.WrapContext(ctx, "cancelled")
+	cancel()
+	ls.Wait("cancelled")
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	ls.WrapContext(ctx, "timed-out")
+	ls.Wait("timed-out")
+}
+
+func TestBridge(t *testing.T) {
+	t.Parallel()
+
+	src := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+	dst1 := src.Clone(&PanicFailer{T: t})
+	dst2 := src.Clone(&PanicFailer{T: t})
+
+	lockstep.Bridge(src, dst1, "x")
+	lockstep.Bridge(src, dst2, "x")
+
+	go src.Emit("x")
+	src.Wait("x")
+	dst1.Wait("x")
+	dst2.Wait("x")
+
+	src.Once("x")
+	dst1.Wait("x")
+	dst2.Wait("x")
+
+	dst1.SetTimeout(10 * time.Millisecond)
+	go src.Emit("y")
+	src.Wait("y")
+	expectFail(t, func() {
+		dst1.Wait("y")
+	})
+}
@@ -0,0 +1,75 @@
+package lockstep
+
+import (
+	"context"
+	"time"
+)
+
+// WithBufferSize limits the number of buffered Emit operations per message.
+// See [LockStep.BufferedEmit]. The default, 0, means unlimited.
+func WithBufferSize(n int) Option {
+	return func(l *LockStep) {
+		l.bufferSize = n
+	}
+}
+
+// BufferedEmit is like Emit, but if there is no Wait operation in progress for
+// m, it stores m in a buffer and returns immediately instead of blocking.
+// Subsequent Wait operations for m consume buffered messages before blocking.
+// This models a buffered channel, where producers can run ahead of consumers.
+//
+// If the buffer for m is full (see [WithBufferSize]), BufferedEmit blocks until
+// there is room or a Wait operation for m starts.
+func (l *LockStep) BufferedEmit(m string) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	l.interceptEmit(m, l.bufferedEmit)
+}
+
+// bufferedEmit is like BufferedEmit, but it bypasses the emit interceptors.
+func (l *LockStep) bufferedEmit(m string) {
+	l.t.Helper()
+
+	l.logf("Emiting %v (buffered)", m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.opTimeout(m))
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	start := time.Now()
+	l.startEmitWithLock(m)
+
+	for {
+		if l.latched[m] || l.matchWithLock(m, start, nil, nil) {
+			l.logf("Emitted %v", m)
+			return
+		}
+
+		if l.bufferSize == 0 || l.buffered[m] < l.bufferSize {
+			l.logf("Buffered %v", m)
+			l.buffered[m]++
+			return
+		}
+
+		if !l.waitWithLock(ctx) {
+			l.recordTimeoutWithLock(OpEmit, m, time.Since(start))
+			l.fail(l.ctxError(ctx, OpEmit, m, "emitting %v (buffer full)", m))
+			return
+		}
+	}
+}
+
+// consumeBufferedWithLock matches the waiter registered for m with the
+// buffered Emit operations for m, if any. l.mu must be held.
+func (l *LockStep) consumeBufferedWithLock(m string, start time.Time) {
+	for l.buffered[m] > 0 && l.matchWithLock(m, start, nil, nil) {
+		l.buffered[m]--
+		if l.buffered[m] == 0 {
+			delete(l.buffered, m)
+		}
+	}
+}
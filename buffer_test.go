@@ -0,0 +1,67 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_BufferedEmit(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	ls.BufferedEmit("x")
+	ls.BufferedEmit("x")
+	ls.BufferedEmit("y")
+	ls.WaitN("x", 2)
+
+	m, ok := ls.TryWait("y")
+	expectEqual(t, true, ok)
+	expectEqual(t, "y", m)
+
+	expectFail(t, func() {
+		ls.Wait("x")
+	})
+
+	// With a Wait in progress, BufferedEmit matches it directly.
+	go ls.BufferedEmit("z")
+	ls.Wait("z")
+}
+
+func TestLockStep_BufferedEmit_Full(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t},
+		lockstep.WithTimeout(100*time.Millisecond),
+		lockstep.WithBufferSize(1))
+
+	ls.BufferedEmit("x")
+	expectFail(t, func() {
+		ls.BufferedEmit("x")
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ls.BufferedEmit("x")
+		close(done)
+	}()
+	ls.Wait("x")
+	<-done
+	ls.Wait("x")
+}
+
+func TestLockStep_BufferedEmit_MustNotEmit(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	time.AfterFunc(50*time.Millisecond, func() {
+		ls.BufferedEmit("x")
+	})
+	expectFail(t, func() {
+		ls.MustNotEmit("x", 300*time.Millisecond)
+	})
+	ls.Wait("x")
+}
@@ -0,0 +1,111 @@
+package lockstep
+
+import (
+	"fmt"
+	"time"
+)
+
+// abortPollInterval is how often AbortOnTestFailure checks whether the test
+// failed.
+const abortPollInterval = 10 * time.Millisecond
+
+// AbortOnTestFailure configures the LockStep to call [LockStep.Cancel] with the
+// reason "test failed" as soon as the test is marked as failed, e.g. because
+// the test goroutine called t.Fatal for an unrelated reason. This releases the
+// goroutines blocked in LockStep instead of letting them time out. Since there
+// is no hook for test failures, t.Failed is polled every 10ms until the test
+// finishes.
+func AbortOnTestFailure() Option {
+	return func(l *LockStep) {
+		l.abortOnFailure = true
+	}
+}
+
+// WithAbortAfter configures the LockStep to call [LockStep.Cancel] once n
+// operations have timed out. In a complex test, the first timeout often
+// causes a cascade of timeouts in the goroutines that depend on it; with
+// WithAbortAfter(1), they fail right away instead. A larger n tolerates up to n
+// independent timeouts. The operations that time out fail with their own
+// timeout, and the reason of the cancellation includes the number of
+// timeouts. By default, timeouts never cancel the LockStep.
+func WithAbortAfter(n int) Option {
+	return func(l *LockStep) {
+		l.abortAfter = n
+	}
+}
+
+// Cancel fails all the Emit and Wait operations in progress immediately, as
+// well as any started later, with the failure "LockStep cancelled: " followed
+// by reason. Use it when a goroutine hits a critical failure, so that the
+// other goroutines blocked in LockStep don't have to time out one by one.
+// Contexts returned by [LockStep.AsContext] are also cancelled.
+//
+// Cancellation is permanent: it is not undone by [LockStep.Reset]. Calling
+// Cancel again has no effect other than keeping the first reason.
+//
+//	go func() {
+//		if err := server.Run(); err != nil {
+//			ls.Cancel("server failed: " + err.Error())
+//		}
+//	}()
+func (l *LockStep) Cancel(reason string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cancelWithLock(reason)
+}
+
+// cancelWithLock is like Cancel. l.mu must be held.
+func (l *LockStep) cancelWithLock(reason string) {
+	l.logf("Cancelled: %v", reason)
+
+	l.cancelReason.CompareAndSwap(nil, &reason)
+	l.broadcastWithLock()
+	l.cancel()
+}
+
+// countTimeoutWithLock counts a timeout, and cancels l if the limit set with
+// WithAbortAfter is reached. l.mu must be held.
+func (l *LockStep) countTimeoutWithLock() {
+	if l.abortAfter <= 0 || l.cancelled() {
+		return
+	}
+	if n := l.timeoutCount.Add(1); n >= int64(l.abortAfter) {
+		l.cancelWithLock(fmt.Sprintf("aborted after %v timeouts", n))
+	}
+}
+
+// cancelled returns true if Cancel was called.
+func (l *LockStep) cancelled() bool {
+	return l.cancelReason.Load() != nil
+}
+
+// cancelledError returns the failure of the operation op for the message m
+// if Cancel was called, or nil otherwise.
+func (l *LockStep) cancelledError(op Op, m string) *LockStepError {
+	reason := l.cancelReason.Load()
+	if reason == nil {
+		return nil
+	}
+	return located(newError(op, m, "cancelled", "LockStep cancelled: %v", *reason))
+}
+
+// abortOnTestFailure polls t.Failed until it returns true, and then cancels l.
+// It returns when l.ctx is done, i.e. when l is cancelled or the test
+// finishes.
+func (l *LockStep) abortOnTestFailure() {
+	ticker := time.NewTicker(abortPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-ticker.C:
+			if l.t.Failed() {
+				l.Cancel("test failed")
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package lockstep_test
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_Cancel(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	errc := make(chan error)
+	go func() {
+		errc <- ls.WaitE("x")
+	}()
+	for ls.PendingCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	begin := time.Now()
+	ls.Cancel("boom")
+	ls.Cancel("ignored")
+
+	err := <-errc
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected Wait to be cancelled right away, took %v", dur)
+	}
+	var lerr *lockstep.LockStepError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("Expected *LockStepError, got %v", err)
+	}
+	expectEqual(t, "cancelled", lerr.Reason)
+	if !strings.HasPrefix(err.Error(), "LockStep cancelled: boom") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expectEqual(t, 0, ls.PendingCount())
+
+	// Later operations fail right away too.
+	expectFail(t, func() {
+		ls.Emit("y")
+	})
+	expectFail(t, func() {
+		ls.Wait("y")
+	})
+}
+
+func TestGroup_Cancel(t *testing.T) {
+	t.Parallel()
+
+	a := lockstep.New(t)
+	b := lockstep.New(t)
+	ctx, cancel := b.AsContext("never")
+	defer cancel()
+
+	lockstep.NewGroup(a, b).Cancel("done")
+
+	if a.EmitE("x") == nil {
+		t.Fatalf("Expected Emit to fail")
+	}
+	<-ctx.Done()
+}
+
+// FailingT is a test context that can be marked as failed without failing the
+// test.
+type FailingT struct {
+	*PanicFailer
+	failed atomic.Bool
+}
+
+func (t *FailingT) Failed() bool {
+	return t.failed.Load()
+}
+
+func TestAbortOnTestFailure(t *testing.T) {
+	t.Parallel()
+
+	ft := &FailingT{PanicFailer: &PanicFailer{T: t}}
+	ls := lockstep.New(ft, lockstep.AbortOnTestFailure())
+
+	errc := make(chan error)
+	go func() {
+		errc <- ls.WaitE("x")
+	}()
+	for ls.PendingCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ft.failed.Store(true)
+	err := <-errc
+	if err == nil || !strings.HasPrefix(err.Error(), "LockStep cancelled: test failed") {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestWithAbortAfter(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithTimeout(50*time.Millisecond), lockstep.WithAbortAfter(1))
+	ls.SetPerMessageTimeout("b", time.Minute)
+
+	start := time.Now()
+	var errA, errB error
+	ls.Coordinate(
+		func() { errA = ls.WaitE("a") },
+		func() { errB = ls.WaitE("b") },
+	)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected the timeout to cancel the LockStep, took %v", elapsed)
+	}
+	if errA == nil || !strings.HasPrefix(errA.Error(), "Timeout waiting for a") {
+		t.Fatalf("Unexpected error: %v", errA)
+	}
+	if errB == nil || !strings.HasPrefix(errB.Error(), "LockStep cancelled: aborted after 1 timeouts") {
+		t.Fatalf("Unexpected error: %v", errB)
+	}
+}
+
+func TestWithAbortAfter_Tolerated(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithTimeout(20*time.Millisecond), lockstep.WithAbortAfter(2))
+
+	var errs []string
+	for _, m := range []string{"a", "b", "c"} {
+		if err := ls.WaitE(m); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	expectEqual(t, 3, len(errs))
+	expectEqual(t, true, strings.HasPrefix(errs[0], "Timeout waiting for a"))
+	expectEqual(t, true, strings.HasPrefix(errs[1], "Timeout waiting for b"))
+	expectEqual(t, true, strings.HasPrefix(errs[2], "LockStep cancelled: aborted after 2 timeouts"))
+}
@@ -0,0 +1,56 @@
+package lockstep
+
+import "strings"
+
+// DefaultSeparator separates the prefix of a child instance from its
+// messages. See [LockStep.Child].
+const DefaultSeparator = ":"
+
+// WithSeparator overrides [DefaultSeparator].
+func WithSeparator(sep string) Option {
+	return func(l *LockStep) {
+		l.separator = sep
+	}
+}
+
+// Child returns a LockStep instance whose messages are namespaced with prefix.
+// The child shares the configuration and state of l: an operation for the
+// message m on the child is the same as an operation for prefix+":"+m on l,
+// where ":" is the separator set using [WithSeparator].
+//
+//	auth := ls.Child("auth")
+//	go auth.Emit("ready")
+//	ls.Wait("auth:ready")
+//
+// This lets reusable test helpers coordinate through LockStep without picking
+// globally unique message names. Children can be nested. Methods that report
+// messages without having been given them, such as Pending and History, use
+// the full message names.
+func (l *LockStep) Child(prefix string) *LockStep {
+	return &LockStep{
+		core:   l.core,
+		prefix: l.prefix + prefix + l.separator,
+	}
+}
+
+// qualify returns the full name of the message m of l.
+func (l *LockStep) qualify(m string) string {
+	return l.prefix + m
+}
+
+// qualifyAll returns the full names of the messages ms of l.
+func (l *LockStep) qualifyAll(ms []string) []string {
+	if l.prefix == "" {
+		return ms
+	}
+	qms := make([]string, len(ms))
+	for i, m := range ms {
+		qms[i] = l.qualify(m)
+	}
+	return qms
+}
+
+// unqualify is the inverse of qualify.
+func (l *LockStep) unqualify(m string) string {
+	return strings.TrimPrefix(m, l.prefix)
+}
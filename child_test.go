@@ -0,0 +1,67 @@
+package lockstep_test
+
+import (
+	"testing"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_Child(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	auth := ls.Child("auth")
+
+	go func() {
+		auth.Emit("ready")
+	}()
+	ls.Wait("auth:ready")
+
+	go func() {
+		ls.Emit("auth:done")
+	}()
+	auth.Wait("done")
+}
+
+func TestLockStep_Child_Nested(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	worker := ls.Child("pool").Child("worker")
+
+	go func() {
+		worker.Emit("started")
+	}()
+	ls.Wait("pool:worker:started")
+}
+
+func TestLockStep_Child_Separator(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithSeparator("/"))
+	db := ls.Child("db")
+
+	db.Once("open")
+	ls.Wait("db/open")
+}
+
+func TestLockStep_Child_Unqualified(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	auth := ls.Child("auth")
+
+	go func() {
+		ls.Emit("auth:y")
+	}()
+	expectEqual(t, "y", auth.WaitAny("x", "y"))
+
+	ls.Once("auth:z")
+	m, ok := auth.TryWait("z")
+	expectEqual(t, true, ok)
+	expectEqual(t, "z", m)
+
+	ls.Once("other:w")
+	ls.Once("auth:w")
+	expectEqual(t, "w", auth.WaitPrefix("w"))
+}
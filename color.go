@@ -0,0 +1,83 @@
+package lockstep
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// ANSI escape sequences used to color verbose logs.
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+)
+
+// logColors maps the prefixes of verbose log messages to their colors: cyan
+// for emits, yellow for waits, green for matches and red for timeouts.
+var logColors = []struct {
+	prefix string
+	color  string
+}{
+	{"Emiting", colorCyan},
+	{"Broadcasting", colorCyan},
+	{"Latching", colorCyan},
+	{"Forwarded", colorCyan},
+	{"Buffered", colorCyan},
+	{"Scheduling emit", colorCyan},
+	{"Emitted", colorGreen},
+	{"Wait satisfied", colorGreen},
+	{"Condition", colorGreen},
+	{"Waiting", colorYellow},
+	{"Expecting", colorYellow},
+	{"Rendezvous", colorYellow},
+	{"Exchanging", colorYellow},
+	{"Polling", colorYellow},
+	{"Checking", colorYellow},
+	{"Timed out", colorRed},
+}
+
+// WithColor enables or disables ANSI colors in verbose logs. By default,
+// colors are used only if verbose logs are written to a terminal and the
+// NO_COLOR environment variable is not set.
+func WithColor(v bool) Option {
+	return func(l *LockStep) {
+		l.color = &v
+	}
+}
+
+// colorize wraps msg in the ANSI color that corresponds to it, if colors are
+// enabled for the destination w. l.outputMu must be held.
+func (l *LockStep) colorize(w io.Writer, msg string) string {
+	if !l.colorEnabled(w) {
+		return msg
+	}
+	for _, c := range logColors {
+		if strings.HasPrefix(msg, c.prefix) {
+			return c.color + msg + colorReset
+		}
+	}
+	return msg
+}
+
+func (l *LockStep) colorEnabled(w io.Writer) bool {
+	if l.color != nil {
+		return *l.color
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(w)
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
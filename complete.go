@@ -0,0 +1,94 @@
+package lockstep
+
+import (
+	"context"
+	"time"
+)
+
+// MustComplete runs fn and fails if it does not return within the given
+// duration. Unlike the timeout of Emit and Wait operations, within applies to
+// the whole execution of fn. fn runs in its own goroutine, which is left
+// running if it does not complete in time. A panic in fn, or fn exiting with
+// runtime.Goexit (e.g. by calling t.Fatalf), is reported as a failure from the
+// calling goroutine.
+//
+//	ls.MustComplete(500*time.Millisecond, func() {
+//		go ls.Emit("request")
+//		ls.Wait("request")
+//	})
+func (l *LockStep) MustComplete(within time.Duration, fn func()) {
+	l.t.Helper()
+
+	name := funcName(fn)
+	l.logf("Running %v for up to %v", name, within)
+
+	ctx, cancel := context.WithTimeout(context.Background(), within)
+	defer cancel()
+
+	// returned is set if fn returns normally, rather than exiting with
+	// runtime.Goexit, e.g. by calling t.Fatalf.
+	returned := false
+	start := time.Now()
+	select {
+	case panicked := <-l.runAsync(func() {
+		fn()
+		returned = true
+	}):
+		switch {
+		case panicked != "":
+			l.checkPanic(panicked)
+		case !returned:
+			l.fail(newError(
+				"complete", name, "goexit",
+				"Expected %v to return, it called runtime.Goexit (e.g. via t.Fatalf)", name))
+		default:
+			l.logf("Completed %v after %v", name, time.Since(start))
+		}
+	case <-ctx.Done():
+		l.fail(newError(
+			"complete", name, "timeout",
+			"Expected %v to complete within %v", name, within))
+	}
+}
+
+// runAsync runs fn in its own goroutine. The returned channel receives the
+// description of the panic in fn, if any, or "" when fn returns.
+func (l *LockStep) runAsync(fn func()) <-chan string {
+	p := l.runGoroutines(1, func(int) { fn() }, nil)
+	done := make(chan string, 1)
+	go func() {
+		done <- p()
+	}()
+	return done
+}
+
+// MustNotComplete runs fn and fails if it returns within the given duration.
+// Use it to check that an operation actually blocks. fn runs in its own
+// goroutine, which is left running after within: the test must make fn
+// return eventually, e.g. by emitting the message it waits for. A panic in fn
+// before within is reported as a failure from the calling goroutine.
+//
+//	ls.MustNotComplete(100*time.Millisecond, func() {
+//		ls.Wait("event")
+//	})
+//	ls.Emit("event")
+func (l *LockStep) MustNotComplete(within time.Duration, fn func()) {
+	l.t.Helper()
+
+	name := funcName(fn)
+	l.logf("Running %v for at least %v", name, within)
+
+	ctx, cancel := context.WithTimeout(context.Background(), within)
+	defer cancel()
+
+	start := time.Now()
+	select {
+	case panicked := <-l.runAsync(fn):
+		l.checkPanic(panicked)
+		l.fail(newError(
+			"complete", name, "completed",
+			"Expected %v not to complete within %v, completed after %v", name, within, time.Since(start)))
+	case <-ctx.Done():
+		l.logf("Still running %v after %v", name, within)
+	}
+}
@@ -0,0 +1,55 @@
+package lockstep_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_MustComplete(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	ls.MustComplete(time.Second, func() {
+		go ls.Emit("x")
+		ls.Wait("x")
+	})
+
+	release := make(chan struct{})
+	defer close(release)
+	expectFail(t, func() {
+		ls.MustComplete(50*time.Millisecond, func() {
+			<-release
+		})
+	})
+
+	expectFail(t, func() {
+		ls.MustComplete(time.Second, func() {
+			panic("boom")
+		})
+	})
+
+	expectFail(t, func() {
+		ls.MustComplete(time.Second, func() {
+			runtime.Goexit()
+		})
+	})
+}
+
+func TestLockStep_MustNotComplete(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	ls.MustNotComplete(50*time.Millisecond, func() {
+		ls.Wait("x")
+	})
+	ls.Emit("x")
+
+	expectFail(t, func() {
+		ls.MustNotComplete(time.Second, func() {})
+	})
+}
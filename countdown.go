@@ -0,0 +1,71 @@
+package lockstep
+
+import "sync"
+
+// CountDown creates a latch that is released after decrement has been called
+// n times. Each call to decrement emits tag+"-decrement". Once all n Emit
+// operations have been processed, done is closed and tag+"-done" is latched
+// (see [LockStep.Once]), so the test can block on either:
+//
+//	decrement, done := ls.CountDown("workers", 3)
+//	for i := 0; i < 3; i++ {
+//		go func() {
+//			// ...
+//			decrement()
+//		}()
+//	}
+//	ls.Wait("workers-done")
+//
+// Calling decrement more than n times fails. The messages tag+"-decrement"
+// and tag+"-done" must not be used for anything else.
+func (l *LockStep) CountDown(tag string, n int) (decrement func(), done <-chan struct{}) {
+	l.t.Helper()
+
+	m := tag + "-decrement"
+	ch := make(chan struct{})
+
+	release := func() {
+		close(ch)
+		l.Once(tag + "-done")
+	}
+
+	if n <= 0 {
+		release()
+	} else {
+		go func() {
+			// Like WaitN, but it stops when the test completes.
+			var err *LockStepError
+			l.interceptWait([]string{l.qualify(m)}, func(ms []string) {
+				err = l.waitN(l.ctx, ms, n)
+			})
+			if err != nil {
+				if l.ctx.Err() == nil {
+					l.fail(err)
+				}
+				return
+			}
+			release()
+		}()
+	}
+
+	var mu sync.Mutex
+	count := 0
+	decrement = func() {
+		l.t.Helper()
+
+		mu.Lock()
+		count++
+		c := count
+		mu.Unlock()
+
+		if c > n {
+			l.fail(newError(
+				"decrement", tag, "too many decrements",
+				"CountDown %v: too many decrements (expected %v)", tag, n))
+			return
+		}
+		l.Emit(m)
+	}
+
+	return decrement, ch
+}
@@ -0,0 +1,76 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_CountDown(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	decrement, done := ls.CountDown("workers", 3)
+	for i := 0; i < 3; i++ {
+		go decrement()
+	}
+	ls.Wait("workers-done")
+	<-done
+
+	expectFail(t, func() {
+		decrement()
+	})
+}
+
+func TestLockStep_CountDown_Zero(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	_, done := ls.CountDown("none", 0)
+	<-done
+	ls.Wait("none-done")
+}
+
+func TestLockStep_CountDown_Interceptors(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	waits := make(chan string, 1)
+	ls.AddWaitInterceptor(func(ms []string, next func(ms []string)) {
+		waits <- ms[0]
+		next(ms)
+	})
+
+	decrement, done := ls.CountDown("workers", 1)
+	decrement()
+	<-done
+	expectEqual(t, "workers-decrement", <-waits)
+}
+
+func TestLockStep_CountDown_Tracer(t *testing.T) {
+	t.Parallel()
+
+	tracer := &fakeTracer{}
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTracer(tracer))
+
+	decrement, done := ls.CountDown("workers", 1)
+	decrement()
+	<-done
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+
+	var wait *fakeSpan
+	for _, s := range tracer.spans {
+		if s.name == "lockstep.wait" && s.m == "workers-decrement" {
+			wait = s
+		}
+	}
+	if wait == nil || len(wait.links) != 1 {
+		t.Fatalf("Expected linked wait span for workers-decrement")
+	}
+}
@@ -0,0 +1,135 @@
+package lockstep
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// WithDeadlockDetection configures the LockStep to detect deadlocks among the
+// goroutines started with [LockStep.RunConcurrent] and [LockStep.Coordinate].
+// When all of them are blocked in LockStep operations at the same time, and
+// none of the operations can make progress, the LockStep is cancelled (see
+// [LockStep.Cancel]) with the reason "deadlock detected", followed by the
+// pending messages. This fails the operations right away, instead of after
+// their timeout expires. Goroutines in operations that end after a given
+// duration, such as MustNotEmit, WaitWithin, Drain and WaitForIdle, are not
+// considered blocked.
+//
+//	ls := lockstep.New(t, lockstep.WithDeadlockDetection(true))
+//	ls.Coordinate(
+//		func() { ls.Wait("a"); ls.Emit("b") },
+//		func() { ls.Wait("b"); ls.Emit("a") },
+//	)
+//
+// Only enable deadlock detection if all the goroutines that emit messages are
+// started with RunConcurrent or Coordinate: a message that would have been
+// emitted by another goroutine, or by EmitAfter, is reported as a deadlock.
+func WithDeadlockDetection(v bool) Option {
+	return func(l *LockStep) {
+		l.deadlockDetection = v
+	}
+}
+
+// trackGoroutines registers n goroutines that are about to start for
+// deadlock detection, if it is enabled. They are registered before they start
+// so that the first ones to block are not mistaken for a deadlock.
+func (l *LockStep) trackGoroutines(n int) {
+	if !l.deadlockDetection {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tracked += n
+}
+
+// startTrackedGoroutine records the ID of the calling goroutine, which was
+// registered with trackGoroutines. The returned function unregisters it, and
+// must be called before the goroutine exits.
+func (l *LockStep) startTrackedGoroutine() func() {
+	if !l.deadlockDetection {
+		return func() {}
+	}
+
+	id := goroutineID()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.goroutines == nil {
+		l.goroutines = make(map[uint64]bool)
+	}
+	l.goroutines[id] = true
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		delete(l.goroutines, id)
+		l.tracked--
+		l.checkDeadlockWithLock()
+	}
+}
+
+// timerBoundKey is the context key of withTimerBound.
+type timerBoundKey struct{}
+
+// withTimerBound returns a copy of ctx for an operation that is expected to
+// be ended by its deadline rather than by a broadcast, such as MustNotEmit or
+// WaitWithin. The goroutines blocked in such operations are not counted as
+// blocked for deadlock detection.
+func withTimerBound(ctx context.Context) context.Context {
+	return context.WithValue(ctx, timerBoundKey{}, true)
+}
+
+// isTimerBound returns true if ctx was returned by withTimerBound.
+func isTimerBound(ctx context.Context) bool {
+	bound, _ := ctx.Value(timerBoundKey{}).(bool)
+	return bound
+}
+
+// blockWithLock records that the calling goroutine is about to block waiting
+// for a broadcast, and checks for a deadlock. If bounded is set, the goroutine
+// will be woken up by a timer (see withTimerBound), so it is not counted as
+// blocked. The returned function must be called, with l.mu held, once the
+// goroutine wakes up. l.mu must be held.
+func (l *LockStep) blockWithLock(bounded bool) func() {
+	if !l.deadlockDetection || bounded || !l.goroutines[goroutineID()] {
+		return func() {}
+	}
+
+	l.blocked++
+	gen := l.blockedGen
+	l.checkDeadlockWithLock()
+
+	return func() {
+		if l.blockedGen == gen {
+			l.blocked--
+		}
+	}
+}
+
+// unblockAllWithLock records that all the blocked goroutines may be able to
+// make progress, because a change was broadcast. l.mu must be held.
+func (l *LockStep) unblockAllWithLock() {
+	l.blockedGen++
+	l.blocked = 0
+}
+
+// checkDeadlockWithLock cancels l if all the goroutines registered for
+// deadlock detection have blocked since the last broadcast, which means none
+// of them will ever be woken up. l.mu must be held.
+func (l *LockStep) checkDeadlockWithLock() {
+	if l.tracked == 0 || l.blocked < l.tracked || l.cancelled() {
+		return
+	}
+
+	l.cancelWithLock(fmt.Sprintf(
+		"deadlock detected: all %v goroutines blocked, waiting for [%v], emitting [%v]",
+		l.tracked, strings.Join(l.pendingWithLock(), ", "),
+		messageList(slices.Sorted(maps.Keys(l.emitting)))))
+}
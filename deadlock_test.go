@@ -0,0 +1,139 @@
+package lockstep_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestDeadlockDetection(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var failures []string
+	ls := lockstep.New(t, lockstep.WithDeadlockDetection(true))
+	ls.SetFailureHandler(func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, fmt.Sprintf(format, args...))
+	})
+
+	start := time.Now()
+	ls.Coordinate(
+		func() {
+			ls.Wait("a")
+			ls.Emit("b")
+		},
+		func() {
+			ls.Wait("b")
+			ls.Emit("a")
+		},
+	)
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected deadlock to be detected before the timeout, took %v", elapsed)
+	}
+	if len(failures) == 0 {
+		t.Fatalf("Expected failures")
+	}
+	for _, f := range failures {
+		if !strings.Contains(f, "LockStep cancelled: deadlock detected: all 2 goroutines blocked, waiting for [a, b]") {
+			t.Fatalf("Unexpected failure: %v", f)
+		}
+	}
+}
+
+func TestDeadlockDetection_NoDeadlock(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithDeadlockDetection(true))
+
+	// Each goroutine waits for the previous one, so all but one of them are
+	// blocked most of the time.
+	const n = 10
+	for i := 0; i < 20; i++ {
+		ls.RunConcurrent(n, func(id int) {
+			if id > 0 {
+				ls.Wait(fmt.Sprint(id - 1))
+			}
+			if id < n-1 {
+				ls.Emit(fmt.Sprint(id))
+			}
+		})
+	}
+}
+
+func TestDeadlockDetection_Disabled(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithTimeout(50*time.Millisecond))
+	var mu sync.Mutex
+	var failures []string
+	ls.SetFailureHandler(func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, fmt.Sprintf(format, args...))
+	})
+
+	ls.Coordinate(
+		func() { ls.Wait("a") },
+		func() { ls.Wait("b") },
+	)
+
+	expectEqual(t, 2, len(failures))
+	for _, f := range failures {
+		if strings.Contains(f, "deadlock detected") {
+			t.Fatalf("Unexpected failure: %v", f)
+		}
+	}
+}
+
+func TestDeadlockDetection_MustNotEmit(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithDeadlockDetection(true))
+
+	// The second goroutine is released by the deadline of MustNotEmit.
+	ls.Coordinate(
+		func() {
+			ls.Wait("a")
+		},
+		func() {
+			ls.MustNotEmit("b", 100*time.Millisecond)
+			ls.Emit("a")
+		},
+	)
+}
+
+func TestDeadlockDetection_WaitWithin(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	var failures []string
+	ls := lockstep.New(t, lockstep.WithDeadlockDetection(true))
+	ls.SetFailureHandler(func(format string, args ...any) {
+		mu.Lock()
+		defer mu.Unlock()
+		failures = append(failures, fmt.Sprintf(format, args...))
+	})
+
+	// The second goroutine is released by the timeout of WaitWithin.
+	ls.Coordinate(
+		func() {
+			ls.Wait("a")
+		},
+		func() {
+			ls.WaitWithin(100*time.Millisecond, "b")
+			ls.Emit("a")
+		},
+	)
+
+	expectEqual(t, 1, len(failures))
+	if !strings.HasPrefix(failures[0], "Timeout waiting for b") {
+		t.Fatalf("Unexpected failure: %v", failures[0])
+	}
+}
@@ -0,0 +1,74 @@
+package lockstep
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// VaryDelay configures the LockStep to inject random delays: each Emit and
+// Wait operation sleeps for a random duration, uniformly distributed between
+// min and max, before it takes the lock. This simulates the scheduling jitter
+// of real systems, and makes race conditions more likely to show up. It is most
+// useful combined with [StressTest]:
+//
+//	lockstep.StressTest(t, 100, func(ls *lockstep.LockStep) {
+//		// ...
+//	}, lockstep.VaryDelay(0, 5*time.Millisecond))
+//
+// The delays are drawn from a math/rand source seeded with the seed configured
+// with [WithSeed]. If no seed is configured, a random one is used and logged,
+// using t.Logf, so that a failure can be reproduced.
+func VaryDelay(min, max time.Duration) Option {
+	return func(l *LockStep) {
+		l.delayMin = min
+		l.delayMax = max
+	}
+}
+
+// WithSeed configures the seed of the random source used for the delays
+// injected by [VaryDelay].
+func WithSeed(seed int64) Option {
+	return func(l *LockStep) {
+		l.seed = seed
+		l.seeded = true
+	}
+}
+
+// newDelayRand creates the random source for the delays injected by VaryDelay,
+// if they are enabled.
+func (l *LockStep) newDelayRand() {
+	if l.delayMax <= 0 {
+		return
+	}
+	if !l.seeded {
+		l.seed = time.Now().UnixNano()
+		l.t.Logf("LockStep: VaryDelay seed %v", l.seed)
+	}
+	l.delayRand = rand.New(rand.NewSource(l.seed))
+}
+
+// delay sleeps for a random duration between l.delayMin and l.delayMax, or
+// until ctx is done. It does nothing if VaryDelay is not enabled.
+func (l *LockStep) delay(ctx context.Context) {
+	if l.delayRand == nil {
+		return
+	}
+
+	d := l.delayMin
+	l.delayMu.Lock()
+	if span := l.delayMax - l.delayMin; span > 0 {
+		d += time.Duration(l.delayRand.Int63n(int64(span) + 1))
+	}
+	l.delayMu.Unlock()
+
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
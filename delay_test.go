@@ -0,0 +1,66 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestVaryDelay(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.VaryDelay(20*time.Millisecond, 30*time.Millisecond), lockstep.WithSeed(1))
+
+	start := time.Now()
+	go ls.Emit("x")
+	ls.Wait("x")
+
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Fatalf("Expected delay of at least 20ms, actual %v", elapsed)
+	}
+}
+
+func TestVaryDelay_LogsSeed(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r, lockstep.VaryDelay(0, time.Millisecond))
+
+	go ls.Emit("x")
+	ls.Wait("x")
+
+	logs := r.Logs()
+	if len(logs) != 1 || !strings.HasPrefix(logs[0], "LockStep: VaryDelay seed ") {
+		t.Fatalf("Unexpected logs: %q", logs)
+	}
+}
+
+func TestVaryDelay_Seeded(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r, lockstep.VaryDelay(0, time.Millisecond), lockstep.WithSeed(42))
+
+	go ls.Emit("x")
+	ls.Wait("x")
+
+	expectEqual(t, 0, len(r.Logs()))
+}
+
+func TestVaryDelay_Timeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t},
+		lockstep.WithTimeout(10*time.Millisecond),
+		lockstep.VaryDelay(time.Hour, time.Hour))
+
+	start := time.Now()
+	expectFail(t, func() {
+		ls.Emit("x")
+	})
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected delay to be bounded by the timeout, actual %v", elapsed)
+	}
+}
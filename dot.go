@@ -0,0 +1,111 @@
+package lockstep
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ExportDOT returns a Graphviz graph of the matched Emit and Wait operations in
+// the history of the LockStep instance. Each goroutine is a node, identified
+// by its goroutine ID, and each matched message m is an edge labeled m from the
+// goroutine that waited for m to the goroutine that emitted it. A message
+// matched more than once between the same goroutines is shown as a single
+// edge, with the number of matches.
+//
+//	os.WriteFile("lockstep.dot", []byte(ls.ExportDOT()), 0o644)
+//	// dot -Tsvg lockstep.dot > lockstep.svg
+func (l *LockStep) ExportDOT() string {
+	type edge struct {
+		from, to uint64
+		m        string
+	}
+
+	var nodes []uint64
+	var edges []edge
+	count := make(map[edge]int)
+	for _, p := range matchPairs(l.History()) {
+		for _, g := range []uint64{p.wait.Goroutine, p.emit.Goroutine} {
+			if !slices.Contains(nodes, g) {
+				nodes = append(nodes, g)
+			}
+		}
+		e := edge{from: p.wait.Goroutine, to: p.emit.Goroutine, m: p.emit.Message}
+		if count[e] == 0 {
+			edges = append(edges, e)
+		}
+		count[e]++
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph lockstep {\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, g := range nodes {
+		fmt.Fprintf(&b, "  g%v [label=\"goroutine %v\"];\n", g, g)
+	}
+	for _, e := range edges {
+		label := e.m
+		if n := count[e]; n > 1 {
+			label = fmt.Sprintf("%v (%v)", e.m, n)
+		}
+		fmt.Fprintf(&b, "  g%v -> g%v [label=%q];\n", e.from, e.to, label)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// matchPair is a matched pair of Emit and Wait operations.
+type matchPair struct {
+	emit, wait, match Event
+}
+
+// matchPairs pairs up the OpEmit and OpWait events in events for each OpMatch
+// event. Operations for the same message are matched in the order they
+// started, the same way LockStep matches them. Emit operations that match
+// several Wait operations, e.g. with Once, are paired with each of them.
+func matchPairs(events []Event) []matchPair {
+	emits := make(map[string][]Event)
+	waits := make(map[string][]Event)
+	lastEmit := make(map[string]Event)
+
+	// remove removes the first event in q from goroutine g.
+	remove := func(q []Event, g uint64) []Event {
+		i := slices.IndexFunc(q, func(e Event) bool { return e.Goroutine == g })
+		if i < 0 {
+			return q
+		}
+		return slices.Delete(q, i, i+1)
+	}
+
+	var pairs []matchPair
+	for _, e := range events {
+		m := e.Message
+		switch e.Op {
+		case OpEmit:
+			emits[m] = append(emits[m], e)
+		case OpWait:
+			waits[m] = append(waits[m], e)
+		case OpTimeout:
+			emits[m] = remove(emits[m], e.Goroutine)
+			waits[m] = remove(waits[m], e.Goroutine)
+		case OpMatch:
+			if len(waits[m]) == 0 {
+				continue
+			}
+			w := waits[m][0]
+			waits[m] = waits[m][1:]
+
+			em, ok := lastEmit[m]
+			if len(emits[m]) > 0 {
+				em, ok = emits[m][0], true
+				emits[m] = emits[m][1:]
+				lastEmit[m] = em
+			}
+			if !ok {
+				continue
+			}
+			pairs = append(pairs, matchPair{emit: em, wait: w, match: e})
+		}
+	}
+	return pairs
+}
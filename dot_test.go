@@ -0,0 +1,46 @@
+package lockstep_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_ExportDOT(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	expectEqual(t, "digraph lockstep {\n  node [shape=box];\n}\n", ls.ExportDOT())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Emit("x")
+		ls.Emit("x")
+		ls.Wait("y")
+	}()
+	ls.Wait("x")
+	ls.Wait("x")
+	ls.Emit("y")
+	<-done
+
+	dot := ls.ExportDOT()
+	lines := strings.Split(strings.TrimSpace(dot), "\n")
+	expectEqual(t, 7, len(lines))
+	expectEqual(t, "digraph lockstep {", lines[0])
+	expectEqual(t, "}", lines[6])
+
+	edge := regexp.MustCompile(`^  g(\d+) -> g(\d+) \[label="(.*)"\];$`)
+	x := edge.FindStringSubmatch(lines[4])
+	y := edge.FindStringSubmatch(lines[5])
+	if x == nil || y == nil {
+		t.Fatalf("Unexpected graph:\n%v", dot)
+	}
+	expectEqual(t, "x (2)", x[3])
+	expectEqual(t, "y", y[3])
+	// The edges go in opposite directions between the same goroutines.
+	expectEqual(t, x[1], y[2])
+	expectEqual(t, x[2], y[1])
+}
@@ -0,0 +1,40 @@
+package lockstep
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// EnvTimeout is the environment variable that overrides DefaultTimeout for
+	// all LockStep instances, e.g. LOCKSTEP_TIMEOUT=1m. It is parsed with
+	// time.ParseDuration.
+	EnvTimeout = "LOCKSTEP_TIMEOUT"
+
+	// EnvVerbose is the environment variable that enables verbose mode for all
+	// LockStep instances, e.g. LOCKSTEP_VERBOSE=1. It is parsed with
+	// strconv.ParseBool.
+	EnvVerbose = "LOCKSTEP_VERBOSE"
+)
+
+// envDefaults applies the environment variables to the default timeout and
+// verbose mode.
+func envDefaults(timeout *time.Duration, verbose *bool) error {
+	if v := os.Getenv(EnvTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("invalid %v: %w", EnvTimeout, err)
+		}
+		*timeout = d
+	}
+	if v := os.Getenv(EnvVerbose); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid %v: %w", EnvVerbose, err)
+		}
+		*verbose = b
+	}
+	return nil
+}
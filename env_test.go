@@ -0,0 +1,64 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+// The tests below are not parallel because t.Setenv affects all the instances
+// created while the environment variables are set.
+
+func TestEnvTimeout(t *testing.T) {
+	t.Setenv(lockstep.EnvTimeout, "50ms")
+
+	ls := lockstep.New(&PanicFailer{T: t})
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.Wait("x")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected timeout from environment, took %v", dur)
+	}
+
+	// SetTimeout takes precedence.
+	ls = lockstep.New(&PanicFailer{T: t})
+	ls.SetTimeout(time.Minute)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+}
+
+func TestEnvVerbose(t *testing.T) {
+	t.Setenv(lockstep.EnvVerbose, "1")
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r)
+	ls.TryEmit("x")
+	expectEqual(t, 1, len(r.Logs()))
+
+	r = &Recorder{T: t}
+	ls = lockstep.New(r)
+	ls.SetVerbose(false)
+	ls.TryEmit("x")
+	expectEqual(t, 0, len(r.Logs()))
+}
+
+func TestEnvInvalid(t *testing.T) {
+	t.Setenv(lockstep.EnvTimeout, "soon")
+
+	var msg string
+	func() {
+		defer func() {
+			msg = string(recover().(FailError))
+		}()
+		lockstep.New(&PanicFailer{T: t})
+	}()
+	if !strings.Contains(msg, "invalid LOCKSTEP_TIMEOUT") {
+		t.Fatalf("Unexpected failure: %q", msg)
+	}
+}
@@ -0,0 +1,201 @@
+package lockstep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// FailureMode determines how a [LockStep] reports failures such as timeouts.
+type FailureMode int
+
+const (
+	// FatalMode reports failures using t.Fatalf, which stops the calling
+	// goroutine. This is the default. Note that t.Fatalf must only be called
+	// from the goroutine running the test.
+	FatalMode FailureMode = iota
+
+	// PanicMode reports failures by panicking with a *LockStepError, which the
+	// caller can recover.
+	PanicMode
+
+	// ErrorMode reports failures using t.Errorf. The failed operation returns
+	// immediately and the test continues.
+	ErrorMode
+)
+
+// LockStepError describes a failed LockStep operation.
+type LockStepError struct {
+	// Op is the operation that failed.
+	Op Op
+	// Message is the message, or list of messages, involved.
+	Message string
+	// Reason is a short description of the failure, e.g. "timeout".
+	Reason string
+	// Location is the file:line of the code that started the failed
+	// operation, if known. It is only set for timeouts and context errors.
+	Location string
+
+	// format and args are the detailed description passed to t.Fatalf.
+	format string
+	args   []any
+}
+
+func newError(op Op, m string, reason string, format string, args ...any) *LockStepError {
+	return &LockStepError{
+		Op:      op,
+		Message: m,
+		Reason:  reason,
+		format:  format,
+		args:    args,
+	}
+}
+
+// newCtxError creates an error for an operation that failed because ctx is
+// done.
+func newCtxError(ctx context.Context, op Op, m string, format string, args ...any) *LockStepError {
+	reason := "timeout"
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		reason = ctx.Err().Error()
+	}
+	return located(newError(op, m, reason, "%v "+format, append([]any{ctxFailure(ctx)}, args...)...))
+}
+
+// located adds the location of the caller to err. See callerLocation.
+func located(err *LockStepError) *LockStepError {
+	if loc := callerLocation(); loc != "" {
+		err.Location = loc
+		err.format += " (at %v)"
+		err.args = append(err.args, loc)
+	}
+	return err
+}
+
+// ctxError is like newCtxError, but if l was cancelled, it creates an error
+// for the cancellation instead, unless the deadline of ctx expired. Operations
+// interrupted by Cancel fail the same way as when ctx is done, so they use it
+// in place of newCtxError.
+func (l *LockStep) ctxError(ctx context.Context, op Op, m string, format string, args ...any) *LockStepError {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return newCtxError(ctx, op, m, format, args...)
+	}
+	if err := l.cancelledError(op, m); err != nil {
+		return err
+	}
+	return newCtxError(ctx, op, m, format, args...)
+}
+
+// callerLocation returns the file:line of the innermost caller outside of
+// this package, i.e. of the code that called into LockStep. It returns "" if
+// there is none, e.g. for operations started from goroutines owned by
+// LockStep.
+func callerLocation() string {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, packagePrefix) && f.File != "" {
+			if strings.HasPrefix(f.Function, "runtime.") || strings.HasPrefix(f.Function, "time.") {
+				return ""
+			}
+			return fmt.Sprintf("%v:%v", filepath.Base(f.File), f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// packagePrefix is the prefix of the names of the functions in this package.
+var packagePrefix = reflect.TypeOf(LockStep{}).PkgPath() + "."
+
+func (e *LockStepError) Error() string {
+	if e.format == "" {
+		return fmt.Sprintf("%v %v: %v", e.Op, e.Message, e.Reason)
+	}
+	return fmt.Sprintf(e.format, e.args...)
+}
+
+// CollectErrors enables error collection mode, and returns l for convenience.
+// In this mode, failures are accumulated instead of being reported, and the
+// failed operation returns immediately. Call [LockStep.FlushErrors] to report
+// the accumulated failures. Error collection mode takes precedence over the
+// failure handler and the failure mode.
+//
+//	ls := lockstep.New(t).CollectErrors()
+//	defer ls.FlushErrors()
+func (l *LockStep) CollectErrors() *LockStep {
+	l.errMu.Lock()
+	defer l.errMu.Unlock()
+
+	l.collect = true
+	return l
+}
+
+// FlushErrors reports each failure accumulated in error collection mode using
+// t.Error, and clears them.
+func (l *LockStep) FlushErrors() {
+	l.t.Helper()
+
+	l.errMu.Lock()
+	errs := l.errs
+	l.errs = nil
+	l.errMu.Unlock()
+
+	for _, err := range errs {
+		l.t.Error(err)
+	}
+}
+
+// fail reports err according to the configuration: it is accumulated in error
+// collection mode, or passed to the failure handler, if any, or reported
+// according to the FailureMode.
+func (l *LockStep) fail(err *LockStepError) {
+	l.t.Helper()
+
+	l.errMu.Lock()
+	collect := l.collect
+	if collect {
+		l.errs = append(l.errs, err)
+	}
+	l.errMu.Unlock()
+	if collect {
+		return
+	}
+
+	format, args := err.format, err.args
+	if format == "" {
+		format, args = "%v", []any{err}
+	}
+
+	if l.failureFunc != nil {
+		l.failureFunc(format, args...)
+		return
+	}
+
+	if err.Reason == "timeout" && l.failureMode != PanicMode {
+		format += "\nGoroutines:\n%v"
+		args = append(args[:len(args):len(args)], DumpGoroutineStacks())
+	}
+
+	switch l.failureMode {
+	case PanicMode:
+		panic(err)
+	case ErrorMode:
+		l.t.Errorf(format, args...)
+	default:
+		l.t.Fatalf(format, args...)
+	}
+}
+
+// ctxFailure describes why ctx is done, for use in failure messages.
+func ctxFailure(ctx context.Context) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "Timeout"
+	}
+	return fmt.Sprintf("Context error (%v)", ctx.Err())
+}
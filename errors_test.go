@@ -0,0 +1,168 @@
+package lockstep_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestFailureMode_Panic(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t,
+		lockstep.WithTimeout(100*time.Millisecond),
+		lockstep.WithFailureMode(lockstep.PanicMode))
+
+	defer func() {
+		err, _ := recover().(error)
+		var lerr *lockstep.LockStepError
+		if !errors.As(err, &lerr) {
+			t.Fatalf("Expected *LockStepError panic, got %v", err)
+		}
+		expectEqual(t, lockstep.OpEmit, lerr.Op)
+		expectEqual(t, "x", lerr.Message)
+		expectEqual(t, "timeout", lerr.Reason)
+		expectLocated(t, "Timeout emitting x", lerr.Error())
+	}()
+
+	ls.Emit("x")
+}
+
+func TestFailureMode_Error(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r,
+		lockstep.WithTimeout(100*time.Millisecond),
+		lockstep.WithFailureMode(lockstep.ErrorMode))
+
+	ls.Wait("x")
+	expectEqual(t, "", ls.WaitAny("y", "z"))
+
+	errs := r.Errors()
+	expectEqual(t, 2, len(errs))
+	for i, e := range []string{"Timeout waiting for x", "Timeout waiting for any of y, z"} {
+		// Timeouts include the goroutine stacks.
+		msg, stacks, ok := strings.Cut(errs[i], "\nGoroutines:\n")
+		expectEqual(t, true, ok)
+		expectLocated(t, e, msg)
+		if !strings.Contains(stacks, "TestFailureMode_Error") {
+			t.Fatalf("Expected stack of the test goroutine in: %v", stacks)
+		}
+	}
+
+	// The failed operations left nothing behind.
+	expectEqual(t, 0, ls.PendingCount())
+}
+
+func TestLockStep_EmitE(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithTimeout(100*time.Millisecond))
+
+	go func() {
+		ls.Wait("x")
+	}()
+	if err := ls.EmitE("x"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	err := ls.EmitE("y")
+	var lerr *lockstep.LockStepError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("Expected *LockStepError, got %v", err)
+	}
+	expectEqual(t, lockstep.OpEmit, lerr.Op)
+	expectEqual(t, "y", lerr.Message)
+	expectEqual(t, "timeout", lerr.Reason)
+}
+
+func TestLockStep_WaitE(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithTimeout(100*time.Millisecond))
+
+	go func() {
+		ls.Emit("x")
+	}()
+	if err := ls.WaitE("x"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	err := ls.WaitE("y", "z")
+	var lerr *lockstep.LockStepError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("Expected *LockStepError, got %v", err)
+	}
+	expectEqual(t, lockstep.OpWait, lerr.Op)
+	expectEqual(t, "y, z", lerr.Message)
+	expectEqual(t, "timeout", lerr.Reason)
+}
+
+func TestLockStep_SetFailureHandler(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithTimeout(100*time.Millisecond))
+
+	var failures []string
+	ls.SetFailureHandler(func(format string, args ...any) {
+		failures = append(failures, fmt.Sprintf(format, args...))
+	})
+
+	ls.Emit("x")
+	ls.Wait("y")
+
+	expectEqual(t, 2, len(failures))
+	expectLocated(t, "Timeout emitting x", failures[0])
+	expectLocated(t, "Timeout waiting for y", failures[1])
+}
+
+func TestLockStep_CollectErrors(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r, lockstep.WithTimeout(100*time.Millisecond)).CollectErrors()
+
+	ls.Emit("x")
+	ls.Wait("y", "z")
+	expectEqual(t, 0, len(r.Errors()))
+
+	ls.FlushErrors()
+	errs := r.Errors()
+	expectEqual(t, 2, len(errs))
+	expectLocated(t, "Timeout emitting x", errs[0])
+	expectLocated(t, "Timeout waiting for y, z", errs[1])
+
+	ls.FlushErrors()
+	expectEqual(t, 2, len(r.Errors()))
+}
+
+func TestLockStepError_Location(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithTimeout(10*time.Millisecond))
+
+	err := ls.WaitE("x")
+	var lerr *lockstep.LockStepError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("Expected *LockStepError, got %v", err)
+	}
+	if !regexp.MustCompile(`^errors_test\.go:\d+$`).MatchString(lerr.Location) {
+		t.Fatalf("Unexpected location %q", lerr.Location)
+	}
+}
+
+// expectLocated checks that the failure a is the failure e followed by the
+// location of the failed operation in this file.
+func expectLocated(t *testing.T, e, a string) {
+	t.Helper()
+	re := "^" + regexp.QuoteMeta(e) + ` \(at errors_test\.go:\d+\)$`
+	if !regexp.MustCompile(re).MatchString(a) {
+		t.Fatalf("Expected: %v (at errors_test.go:N) Actual: %v", e, a)
+	}
+}
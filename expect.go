@@ -0,0 +1,105 @@
+package lockstep
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+// expectedSequence is a sequence declared with ExpectSequence.
+type expectedSequence struct {
+	done chan struct{}
+	err  *LockStepError
+}
+
+// ExpectSequence declares that the messages in steps are expected to be
+// emitted in order, with no other messages emitted between the first and the
+// last of them. It does not block: the steps are waited for, as in
+// WaitOrdered, from a separate goroutine. Call VerifySequence to wait for the
+// sequence to complete and report any failure.
+//
+//	ls.ExpectSequence("idle", "connecting", "connected")
+//	sm.Start()
+//	ls.VerifySequence()
+//
+// Only one sequence can be expected at a time.
+func (l *LockStep) ExpectSequence(steps ...string) {
+	l.t.Helper()
+
+	steps = l.qualifyAll(steps)
+
+	l.logf("Expecting sequence %v", strings.Join(steps, ", "))
+
+	s := &expectedSequence{done: make(chan struct{})}
+
+	l.mu.Lock()
+	l.sequence = s
+	l.mu.Unlock()
+
+	go func() {
+		defer close(s.done)
+
+		ctx, cancel := context.WithTimeout(l.ctx, l.opTimeout(steps...))
+		defer cancel()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		s.err = l.expectSequenceWithLock(ctx, steps)
+	}()
+}
+
+// expectSequenceWithLock waits for steps in order, and then checks that no
+// other messages were emitted in between. l.mu must be held.
+func (l *LockStep) expectSequenceWithLock(ctx context.Context, steps []string) *LockStepError {
+	start := len(l.history)
+	for i := range steps {
+		if err := l.waitOrderedWithLock(ctx, steps[i:]); err != nil {
+			return err
+		}
+	}
+
+	if len(steps) < 2 {
+		return nil
+	}
+
+	inSequence := false
+	for _, e := range l.history[start:] {
+		if !inSequence {
+			inSequence = e.Op == OpMatch && e.Message == steps[0]
+			continue
+		}
+		if e.Op == OpMatch && e.Message == steps[len(steps)-1] {
+			break
+		}
+		if e.Op == OpEmit && !slices.Contains(steps, e.Message) {
+			seq := strings.Join(steps, ", ")
+			return newError(
+				OpWait, seq, "unexpected emit",
+				"Unexpected emit of %v in sequence %v", e.Message, seq)
+		}
+	}
+	return nil
+}
+
+// VerifySequence waits for the sequence declared with ExpectSequence to
+// complete, and fails if it did not complete in order, or if other messages
+// were emitted in the middle of it. It does nothing if no sequence was
+// expected.
+func (l *LockStep) VerifySequence() {
+	l.t.Helper()
+
+	l.mu.Lock()
+	s := l.sequence
+	l.sequence = nil
+	l.mu.Unlock()
+
+	if s == nil {
+		return
+	}
+
+	<-s.done
+	if s.err != nil {
+		l.fail(s.err)
+	}
+}
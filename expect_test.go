@@ -0,0 +1,53 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_ExpectSequence(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	ls.ExpectSequence("a", "b", "c")
+	go func() {
+		ls.Emit("a")
+		ls.Emit("b")
+		ls.Emit("c")
+	}()
+	ls.VerifySequence()
+
+	// Nothing to verify.
+	ls.VerifySequence()
+}
+
+func TestLockStep_ExpectSequence_Unexpected(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	ls.ExpectSequence("a", "b")
+	go func() {
+		ls.Emit("a")
+		ls.Once("x")
+		ls.Emit("b")
+	}()
+	expectFail(t, func() {
+		ls.VerifySequence()
+	})
+}
+
+func TestLockStep_ExpectSequence_Incomplete(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(50*time.Millisecond))
+
+	ls.ExpectSequence("a", "b")
+	go ls.Emit("a")
+	expectFail(t, func() {
+		ls.VerifySequence()
+	})
+}
@@ -0,0 +1,159 @@
+package lockstep
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// WithMaxPermutations limits the number of orderings tested by
+// [ExploreOrderings] to k. By default, all of them are tested.
+func WithMaxPermutations(k int) Option {
+	return func(l *LockStep) {
+		l.maxPermutations = k
+	}
+}
+
+// ExploreOrderings runs fn once for each permutation of messages, in a
+// sub-test named after the permutation. In each run, the first Emit operation
+// for each of the messages is held back until those for the messages before it
+// in the permutation have started, which forces the messages to be emitted in
+// every possible order. This surfaces deadlocks and other bugs that only show
+// up when messages arrive in a specific order:
+//
+//	lockstep.ExploreOrderings(t, []string{"a", "b", "c"}, func(ls *lockstep.LockStep) {
+//		go func() { ls.Emit("a") }()
+//		go func() { ls.Emit("b") }()
+//		go func() { ls.Emit("c") }()
+//		ls.Wait("a", "b", "c")
+//	})
+//
+// Each run uses a new LockStep instance bound to the sub-test and created with
+// opts. An Emit operation held back for longer than its timeout fails like any
+// other timeout. For n messages there are n! permutations; use
+// [WithMaxPermutations] to limit how many are tested. Permutations are tested in
+// lexicographic order of the positions of the messages, starting with messages
+// itself.
+func ExploreOrderings(t *testing.T, messages []string, fn func(*LockStep), opts ...Option) {
+	t.Helper()
+
+	perm := make([]int, len(messages))
+	for i := range perm {
+		perm[i] = i
+	}
+
+	limit := 0
+	for n := 0; limit <= 0 || n < limit; n++ {
+		order := make([]string, len(perm))
+		for i, p := range perm {
+			order[i] = messages[p]
+		}
+
+		t.Run(strings.Join(order, ","), func(t *testing.T) {
+			l := New(t, opts...)
+			limit = l.maxPermutations
+			l.ordering = newOrdering(order)
+			fn(l)
+		})
+
+		if !nextPermutation(perm) {
+			break
+		}
+	}
+}
+
+// nextPermutation rearranges perm into the next permutation in lexicographic
+// order. It returns false if perm is the last one.
+func nextPermutation(perm []int) bool {
+	i := len(perm) - 2
+	for i >= 0 && perm[i] >= perm[i+1] {
+		i--
+	}
+	if i < 0 {
+		return false
+	}
+	j := len(perm) - 1
+	for perm[j] <= perm[i] {
+		j--
+	}
+	perm[i], perm[j] = perm[j], perm[i]
+	for a, b := i+1, len(perm)-1; a < b; a, b = a+1, b-1 {
+		perm[a], perm[b] = perm[b], perm[a]
+	}
+	return true
+}
+
+// ordering is the order in which messages must be emitted. See
+// ExploreOrderings.
+type ordering struct {
+	order []string
+
+	// started[i] is closed when the first Emit operation for order[i] starts.
+	started []chan struct{}
+	once    []sync.Once
+}
+
+func newOrdering(order []string) *ordering {
+	o := &ordering{
+		order:   order,
+		started: make([]chan struct{}, len(order)),
+		once:    make([]sync.Once, len(order)),
+	}
+	for i := range o.started {
+		o.started[i] = make(chan struct{})
+	}
+	return o
+}
+
+// index returns the position of m in the order, or -1.
+func (o *ordering) index(m string) int {
+	for i, om := range o.order {
+		if om == m {
+			return i
+		}
+	}
+	return -1
+}
+
+// awaitTurn blocks until m can be emitted, i.e. until the Emit operation for
+// the message before m in the ordering of l has started. It does nothing if l
+// has no ordering.
+func (l *LockStep) awaitTurn(ctx context.Context, m string) *LockStepError {
+	if l.ordering == nil {
+		return nil
+	}
+	i := l.ordering.index(m)
+	if i <= 0 {
+		return nil
+	}
+
+	start := time.Now()
+	select {
+	case <-l.ordering.started[i-1]:
+		return nil
+	case <-ctx.Done():
+	}
+
+	prev := l.ordering.order[i-1]
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.recordTimeoutWithLock(OpEmit, m, time.Since(start))
+	return l.ctxError(ctx, OpEmit, m, "emitting %v after %v", m, prev)
+}
+
+// startTurnWithLock records that an Emit operation for m started, allowing the
+// next message in the ordering of l to be emitted. l.mu must be held.
+func (l *LockStep) startTurnWithLock(m string) {
+	if l.ordering == nil {
+		return
+	}
+	if i := l.ordering.index(m); i >= 0 {
+		l.ordering.once[i].Do(func() {
+			close(l.ordering.started[i])
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package lockstep_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+// emitOrder returns the messages emitted by ls, in order.
+func emitOrder(ls *lockstep.LockStep) string {
+	var ms []string
+	for _, e := range ls.History() {
+		if e.Op == lockstep.OpEmit {
+			ms = append(ms, e.Message)
+		}
+	}
+	return strings.Join(ms, ",")
+}
+
+func TestExploreOrderings(t *testing.T) {
+	t.Parallel()
+
+	var orders []string
+	lockstep.ExploreOrderings(t, []string{"a", "b", "c"}, func(ls *lockstep.LockStep) {
+		for _, m := range []string{"c", "b", "a"} {
+			go ls.Emit(m)
+		}
+		ls.Wait("a", "b", "c")
+		orders = append(orders, emitOrder(ls))
+	})
+
+	expectEqual(t, "a,b,c a,c,b b,a,c b,c,a c,a,b c,b,a", strings.Join(orders, " "))
+}
+
+func TestExploreOrderings_MaxPermutations(t *testing.T) {
+	t.Parallel()
+
+	n := 0
+	lockstep.ExploreOrderings(t, []string{"a", "b", "c", "d"}, func(ls *lockstep.LockStep) {
+		n++
+	}, lockstep.WithMaxPermutations(5))
+
+	expectEqual(t, 5, n)
+}
+
+func TestExploreOrderings_Deadlock(t *testing.T) {
+	t.Parallel()
+
+	var mu sync.Mutex
+	fails := 0
+	countFailures := func(l *lockstep.LockStep) {
+		l.SetFailureHandler(func(format string, args ...any) {
+			mu.Lock()
+			defer mu.Unlock()
+			fails++
+		})
+	}
+
+	var failed []bool
+	lockstep.ExploreOrderings(t, []string{"a", "b"}, func(ls *lockstep.LockStep) {
+		mu.Lock()
+		before := fails
+		mu.Unlock()
+
+		// The emitter always emits a before b, so the ordering b,a can't
+		// complete.
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			ls.Emit("a")
+			ls.Emit("b")
+		}()
+		ls.Wait("a", "b")
+		<-done
+
+		mu.Lock()
+		failed = append(failed, fails > before)
+		mu.Unlock()
+	}, lockstep.WithTimeout(50*time.Millisecond), countFailures)
+
+	expectEqual(t, 2, len(failed))
+	expectEqual(t, false, failed[0])
+	expectEqual(t, true, failed[1])
+}
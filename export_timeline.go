@@ -0,0 +1,440 @@
+package lockstep
+
+import (
+	"html/template"
+	"io"
+	"slices"
+	"time"
+)
+
+// timelineSpan is an Emit or Wait operation shown in the HTML timeline.
+// Start and End are in microseconds since the first event.
+type timelineSpan struct {
+	Op        Op      `json:"op"`
+	Message   string  `json:"message"`
+	Goroutine uint64  `json:"goroutine"`
+	Start     float64 `json:"start"`
+	End       float64 `json:"end"`
+	// Outcome is "match", "timeout" or "pending".
+	Outcome string `json:"outcome"`
+}
+
+// timelineArrow connects the Emit and Wait spans of a match. Emit and Wait
+// are indexes into the spans, and Time is when the match happened.
+type timelineArrow struct {
+	Emit int     `json:"emit"`
+	Wait int     `json:"wait"`
+	Time float64 `json:"time"`
+}
+
+type timelineData struct {
+	Title      string          `json:"title"`
+	Span       float64         `json:"span"`
+	Goroutines []uint64        `json:"goroutines"`
+	Spans      []timelineSpan  `json:"spans"`
+	Arrows     []timelineArrow `json:"arrows"`
+}
+
+// ExportTimeline writes to w a self-contained HTML page with an interactive
+// chart of the events in the history of the LockStep instance. Each goroutine
+// is shown as a horizontal swimlane, Emit and Wait operations are shown as
+// labeled boxes from their start until they were matched or timed out, and
+// each match is shown as an arrow from the Emit to the Wait operation. The
+// chart can be zoomed with the mouse wheel, panned by dragging and reset with
+// a double click.
+//
+//	f, _ := os.Create("timeline.html")
+//	defer f.Close()
+//	ls.ExportTimeline(f)
+func (l *LockStep) ExportTimeline(w io.Writer) error {
+	return timelineTemplate.Execute(w, l.timelineData())
+}
+
+func (l *LockStep) timelineData() timelineData {
+	title := "LockStep timeline"
+	if l.name != "" {
+		title += " - " + l.name
+	}
+	data := timelineData{Title: title}
+
+	events := l.History()
+	if len(events) == 0 {
+		return data
+	}
+
+	start := events[0].Time
+	offset := func(t time.Time) float64 {
+		return float64(t.Sub(start)) / float64(time.Microsecond)
+	}
+	data.Span = offset(events[len(events)-1].Time)
+
+	spans := make(map[Event]int)
+	for _, e := range events {
+		if e.Op != OpEmit && e.Op != OpWait {
+			continue
+		}
+		if !slices.Contains(data.Goroutines, e.Goroutine) {
+			data.Goroutines = append(data.Goroutines, e.Goroutine)
+		}
+		spans[e] = len(data.Spans)
+		data.Spans = append(data.Spans, timelineSpan{
+			Op:        e.Op,
+			Message:   e.Message,
+			Goroutine: e.Goroutine,
+			Start:     offset(e.Time),
+			End:       -1,
+		})
+	}
+	slices.Sort(data.Goroutines)
+
+	for _, p := range matchPairs(events) {
+		t := offset(p.match.Time)
+		ei, wi := spans[p.emit], spans[p.wait]
+		data.Spans[ei].End = max64(data.Spans[ei].End, t)
+		data.Spans[ei].Outcome = "match"
+		data.Spans[wi].End = t
+		data.Spans[wi].Outcome = "match"
+		data.Arrows = append(data.Arrows, timelineArrow{Emit: ei, Wait: wi, Time: t})
+	}
+
+	for _, e := range events {
+		if e.Op != OpTimeout {
+			continue
+		}
+		for i := range data.Spans {
+			s := &data.Spans[i]
+			if s.End < 0 && s.Message == e.Message && s.Goroutine == e.Goroutine {
+				s.End = offset(e.Time)
+				s.Outcome = "timeout"
+				break
+			}
+		}
+	}
+
+	for i := range data.Spans {
+		if s := &data.Spans[i]; s.End < 0 {
+			s.End = data.Span
+			s.Outcome = "pending"
+		}
+	}
+
+	return data
+}
+
+func max64(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+var timelineTemplate = template.Must(template.New("timeline").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+  body {
+    margin: 0;
+    font: 12px sans-serif;
+    color: #222;
+    background: #fff;
+  }
+  header {
+    padding: 8px 12px;
+    border-bottom: 1px solid #ddd;
+  }
+  header h1 {
+    display: inline;
+    font-size: 16px;
+    margin-right: 16px;
+  }
+  header .legend span {
+    display: inline-block;
+    margin-right: 12px;
+  }
+  header .legend i {
+    display: inline-block;
+    width: 12px;
+    height: 12px;
+    margin-right: 4px;
+    vertical-align: middle;
+  }
+  #chart {
+    width: 100%;
+    cursor: grab;
+    user-select: none;
+  }
+  #chart.dragging {
+    cursor: grabbing;
+  }
+  .lane {
+    fill: #fafafa;
+    stroke: #eee;
+  }
+  .lane.odd {
+    fill: #f2f2f2;
+  }
+  .lane-label {
+    font-weight: bold;
+  }
+  .tick line {
+    stroke: #ddd;
+  }
+  .tick text {
+    fill: #666;
+  }
+  .span rect {
+    stroke-width: 1;
+    rx: 3;
+  }
+  .span.emit rect {
+    fill: #cfe3ff;
+    stroke: #3b7dd8;
+  }
+  .span.wait rect {
+    fill: #d8f5d0;
+    stroke: #3a9a2a;
+  }
+  .span.timeout rect {
+    fill: #ffd6d6;
+    stroke: #c62828;
+  }
+  .span.pending rect {
+    stroke-dasharray: 4 2;
+  }
+  .span text {
+    pointer-events: none;
+  }
+  .arrow {
+    fill: none;
+    stroke: #888;
+    stroke-width: 1.2;
+    marker-end: url(#head);
+  }
+  .empty {
+    padding: 12px;
+  }
+</style>
+</head>
+<body>
+<header>
+  <h1>{{.Title}}</h1>
+  <span class="legend">
+    <span><i style="background:#cfe3ff;border:1px solid #3b7dd8"></i>emit</span>
+    <span><i style="background:#d8f5d0;border:1px solid #3a9a2a"></i>wait</span>
+    <span><i style="background:#ffd6d6;border:1px solid #c62828"></i>timeout</span>
+    <span>wheel: zoom, drag: pan, double click: reset</span>
+  </span>
+</header>
+<div id="root"></div>
+<script>
+(function() {
+  "use strict";
+
+  var data = {{.}};
+
+  var root = document.getElementById("root");
+  if (!data.spans || data.spans.length === 0) {
+    root.innerHTML = '<div class="empty">No events.</div>';
+    return;
+  }
+
+  var SVG = "http://www.w3.org/2000/svg";
+  var labelWidth = 140;
+  var laneHeight = 44;
+  var boxHeight = 20;
+  var axisHeight = 24;
+  var lanes = {};
+  data.goroutines.forEach(function(g, i) { lanes[g] = i; });
+
+  var svg = document.createElementNS(SVG, "svg");
+  svg.id = "chart";
+  svg.setAttribute("height", axisHeight + data.goroutines.length * laneHeight + 8);
+  root.appendChild(svg);
+
+  var defs = el("defs", {}, svg);
+  var marker = el("marker", {
+    id: "head", viewBox: "0 0 10 10", refX: 10, refY: 5,
+    markerWidth: 6, markerHeight: 6, orient: "auto-start-reverse"
+  }, defs);
+  el("path", {d: "M 0 0 L 10 5 L 0 10 z", fill: "#888"}, marker);
+
+  var full = {start: 0, end: Math.max(data.span, 1)};
+  var view = {start: full.start, end: full.end};
+
+  function el(name, attrs, parent) {
+    var e = document.createElementNS(SVG, name);
+    for (var k in attrs) {
+      e.setAttribute(k, attrs[k]);
+    }
+    if (parent) {
+      parent.appendChild(e);
+    }
+    return e;
+  }
+
+  function width() {
+    return svg.clientWidth || 1000;
+  }
+
+  function x(t) {
+    var w = width() - labelWidth - 16;
+    return labelWidth + (t - view.start) / (view.end - view.start) * w;
+  }
+
+  function laneY(g) {
+    return axisHeight + lanes[g] * laneHeight;
+  }
+
+  function formatTime(us) {
+    if (Math.abs(us) >= 1e6) {
+      return (us / 1e6).toFixed(3) + "s";
+    }
+    if (Math.abs(us) >= 1e3) {
+      return (us / 1e3).toFixed(3) + "ms";
+    }
+    return us.toFixed(1) + "µs";
+  }
+
+  function niceStep(range, count) {
+    var raw = range / count;
+    var mag = Math.pow(10, Math.floor(Math.log10(raw)));
+    var norm = raw / mag;
+    var step = norm < 1.5 ? 1 : norm < 3.5 ? 2 : norm < 7.5 ? 5 : 10;
+    return step * mag;
+  }
+
+  function drawAxis(g) {
+    var step = niceStep(view.end - view.start, 10);
+    var first = Math.ceil(view.start / step) * step;
+    var bottom = axisHeight + data.goroutines.length * laneHeight;
+    for (var t = first; t <= view.end; t += step) {
+      var tick = el("g", {"class": "tick"}, g);
+      el("line", {x1: x(t), x2: x(t), y1: axisHeight - 4, y2: bottom}, tick);
+      var text = el("text", {x: x(t) + 2, y: axisHeight - 8}, tick);
+      text.textContent = formatTime(t);
+    }
+  }
+
+  function drawLanes(g) {
+    data.goroutines.forEach(function(id, i) {
+      el("rect", {
+        "class": "lane" + (i % 2 ? " odd" : ""),
+        x: 0, y: laneY(id), width: width(), height: laneHeight
+      }, g);
+      var label = el("text", {
+        "class": "lane-label", x: 8, y: laneY(id) + laneHeight / 2 + 4
+      }, g);
+      label.textContent = "goroutine " + id;
+    });
+  }
+
+  function spanBox(s) {
+    var x1 = x(s.start);
+    var x2 = Math.max(x(s.end), x1 + 4);
+    var offset = s.op === "emit" ? 2 : laneHeight - boxHeight - 2;
+    return {x: x1, y: laneY(s.goroutine) + offset, w: x2 - x1, h: boxHeight};
+  }
+
+  function drawSpans(g) {
+    data.spans.forEach(function(s) {
+      var b = spanBox(s);
+      if (b.x + b.w < labelWidth || b.x > width()) {
+        return;
+      }
+      var cls = "span " + s.op;
+      if (s.outcome !== "match") {
+        cls += " " + s.outcome;
+      }
+      var group = el("g", {"class": cls}, g);
+      el("rect", {x: b.x, y: b.y, width: b.w, height: b.h}, group);
+      var title = el("title", {}, group);
+      title.textContent = s.op + " " + s.message + " (goroutine " + s.goroutine + ")\n" +
+        formatTime(s.start) + " - " + formatTime(s.end) + " [" + s.outcome + "]";
+      if (b.w > 24) {
+        var text = el("text", {x: b.x + 4, y: b.y + boxHeight / 2 + 4}, group);
+        text.textContent = s.message;
+      }
+    });
+  }
+
+  function drawArrows(g) {
+    data.arrows.forEach(function(a) {
+      var e = spanBox(data.spans[a.emit]);
+      var w = spanBox(data.spans[a.wait]);
+      var ax = x(a.time);
+      var y1 = e.y + (w.y >= e.y ? e.h : 0);
+      var y2 = w.y + (w.y >= e.y ? 0 : w.h);
+      var bend = Math.max(Math.abs(y2 - y1) / 2, 16);
+      var d = "M " + ax + " " + y1 +
+        " C " + (ax + bend) + " " + y1 + ", " + (ax + bend) + " " + y2 + ", " + ax + " " + y2;
+      el("path", {"class": "arrow", d: d}, g);
+    });
+  }
+
+  function render() {
+    var old = svg.querySelector("g.content");
+    if (old) {
+      svg.removeChild(old);
+    }
+    var g = el("g", {"class": "content"}, svg);
+    drawLanes(g);
+    drawAxis(g);
+    drawSpans(g);
+    drawArrows(g);
+  }
+
+  function timeAt(px) {
+    var w = width() - labelWidth - 16;
+    return view.start + (px - labelWidth) / w * (view.end - view.start);
+  }
+
+  svg.addEventListener("wheel", function(ev) {
+    ev.preventDefault();
+    var rect = svg.getBoundingClientRect();
+    var t = timeAt(ev.clientX - rect.left);
+    var factor = ev.deltaY < 0 ? 0.8 : 1.25;
+    var start = t - (t - view.start) * factor;
+    var end = t + (view.end - t) * factor;
+    if (end - start < 0.01) {
+      return;
+    }
+    view.start = Math.max(full.start, start);
+    view.end = Math.min(full.end, end);
+    render();
+  }, {passive: false});
+
+  var drag = null;
+  svg.addEventListener("mousedown", function(ev) {
+    drag = {x: ev.clientX, start: view.start, end: view.end};
+    svg.classList.add("dragging");
+  });
+  window.addEventListener("mousemove", function(ev) {
+    if (!drag) {
+      return;
+    }
+    var w = width() - labelWidth - 16;
+    var dt = (drag.x - ev.clientX) / w * (drag.end - drag.start);
+    dt = Math.max(full.start - drag.start, Math.min(full.end - drag.end, dt));
+    view.start = drag.start + dt;
+    view.end = drag.end + dt;
+    render();
+  });
+  window.addEventListener("mouseup", function() {
+    drag = null;
+    svg.classList.remove("dragging");
+  });
+  svg.addEventListener("dblclick", function() {
+    view.start = full.start;
+    view.end = full.end;
+    render();
+  });
+  window.addEventListener("resize", render);
+
+  render();
+})();
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,43 @@
+package lockstep_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_ExportTimeline(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.NewNamed(r, "</script>", lockstep.WithTimeout(50*time.Millisecond),
+		lockstep.WithFailureMode(lockstep.ErrorMode))
+
+	go ls.Emit("x")
+	ls.Wait("x")
+	ls.Wait("never")
+
+	var buf bytes.Buffer
+	if err := ls.ExportTimeline(&buf); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	if !strings.HasPrefix(html, "<!DOCTYPE html>") {
+		t.Fatalf("Unexpected output: %q", html[:32])
+	}
+	for _, want := range []string{
+		`"op":"emit","message":"x"`,
+		`"op":"wait","message":"x"`,
+		`"outcome":"timeout"`,
+		`"arrows":[{"emit":`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Fatalf("Expected %q in output", want)
+		}
+	}
+	// The name must be escaped so that it cannot end the script.
+	expectEqual(t, 1, strings.Count(html, "</script>"))
+}
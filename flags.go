@@ -0,0 +1,75 @@
+package lockstep
+
+import (
+	"flag"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	timeoutFlag = "lockstep.timeout"
+	verboseFlag = "lockstep.verbose"
+)
+
+// registerMu serializes RegisterFlags.
+var registerMu sync.Mutex
+
+// RegisterFlags registers the -lockstep.timeout and -lockstep.verbose flags in
+// [flag.CommandLine]. Once the flags are parsed, LockStep instances created
+// with [New] use the values of the flags set in the command line as the
+// default timeout and verbose mode, instead of the environment variables (see
+// [EnvTimeout]), even if they are set to their default values. Options and
+// setters such as [LockStep.SetTimeout] still take precedence.
+//
+// RegisterFlags must be called before the flags are parsed, e.g. from
+// TestMain or init. Calling it more than once has no effect. See
+// [TestMainHelper].
+func RegisterFlags() {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	if flag.Lookup(timeoutFlag) != nil {
+		return
+	}
+	flag.Duration(timeoutFlag, DefaultTimeout,
+		"default timeout for LockStep Emit and Wait operations")
+	flag.Bool(verboseFlag, false,
+		"enable verbose mode for all LockStep instances")
+}
+
+// TestMainHelper registers the LockStep flags, parses the command line, runs
+// the tests and returns the exit code.
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(lockstep.TestMainHelper(m))
+//	}
+//
+//	// go test -lockstep.timeout=1m -lockstep.verbose
+func TestMainHelper(m *testing.M) int {
+	RegisterFlags()
+	flag.Parse()
+	return m.Run()
+}
+
+// defaults returns the default timeout and verbose mode for new instances:
+// DefaultTimeout and no verbose mode, overridden by the environment variables
+// (see [EnvTimeout]), overridden in turn by the flags set in the command line.
+func defaults() (timeout time.Duration, verbose bool, err error) {
+	timeout = DefaultTimeout
+	if err := envDefaults(&timeout, &verbose); err != nil {
+		return timeout, verbose, err
+	}
+	if flag.Parsed() {
+		// Only the flags that were set override the environment.
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case timeoutFlag:
+				timeout = f.Value.(flag.Getter).Get().(time.Duration)
+			case verboseFlag:
+				verbose = f.Value.(flag.Getter).Get().(bool)
+			}
+		})
+	}
+	return timeout, verbose, nil
+}
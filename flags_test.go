@@ -0,0 +1,65 @@
+package lockstep_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+// parseFlags replaces flag.CommandLine, for the duration of the test, with a
+// new flag set with the LockStep flags, and parses args with it.
+func parseFlags(t *testing.T, args ...string) {
+	saved := flag.CommandLine
+	t.Cleanup(func() {
+		flag.CommandLine = saved
+	})
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+
+	lockstep.RegisterFlags()
+	lockstep.RegisterFlags()
+	if err := flag.CommandLine.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The tests below are not parallel because the flags affect all the instances
+// created while they are set.
+
+func TestRegisterFlags(t *testing.T) {
+	parseFlags(t, "-lockstep.timeout=50ms")
+
+	ls := lockstep.New(&PanicFailer{T: t})
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.Wait("x")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected timeout from flag, took %v", dur)
+	}
+
+	ls = lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Minute))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+}
+
+func TestRegisterFlags_OverrideEnv(t *testing.T) {
+	t.Setenv(lockstep.EnvTimeout, "50ms")
+	t.Setenv(lockstep.EnvVerbose, "1")
+	// Flags set to their default values still take precedence.
+	parseFlags(t, "-lockstep.timeout="+lockstep.DefaultTimeout.String(), "-lockstep.verbose=false")
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+	expectEqual(t, 0, len(r.Logs()))
+}
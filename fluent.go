@@ -0,0 +1,64 @@
+package lockstep
+
+import "slices"
+
+// Chain is a sequence of Emit and Wait operations that run one after the
+// other. Create one using [LockStep.Fluent].
+//
+// A Chain is immutable: each method returns a new Chain, so a Chain can be
+// extended in different ways and run any number of times.
+type Chain struct {
+	l     *LockStep
+	steps []func() error
+}
+
+// Fluent returns an empty Chain for l.
+//
+//	err := ls.Fluent().Wait("init").Emit("start").Wait("result").Emit("done").Run()
+func (l *LockStep) Fluent() *Chain {
+	return &Chain{l: l}
+}
+
+// Wait returns a Chain that, after the steps of c, waits for all the messages
+// in ms. See [LockStep.Wait].
+func (c *Chain) Wait(ms ...string) *Chain {
+	ms = slices.Clone(ms)
+	return c.then(func() error {
+		return c.l.WaitE(ms...)
+	})
+}
+
+// Emit returns a Chain that, after the steps of c, emits m. See
+// [LockStep.Emit].
+func (c *Chain) Emit(m string) *Chain {
+	return c.then(func() error {
+		return c.l.EmitE(m)
+	})
+}
+
+// Then returns a Chain that runs the steps of c followed by the steps of
+// next.
+func (c *Chain) Then(next *Chain) *Chain {
+	return &Chain{
+		l:     c.l,
+		steps: append(slices.Clip(c.steps), next.steps...),
+	}
+}
+
+// Run runs the steps of c in order, each blocking until it is done. It stops
+// at the first step that fails, and returns its *LockStepError.
+func (c *Chain) Run() error {
+	for _, step := range c.steps {
+		if err := step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Chain) then(step func() error) *Chain {
+	return &Chain{
+		l:     c.l,
+		steps: append(slices.Clip(c.steps), step),
+	}
+}
@@ -0,0 +1,69 @@
+package lockstep_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestChain_Run(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	done := make(chan error)
+	go func() {
+		done <- ls.Fluent().Wait("init").Emit("start").Wait("result").Emit("done").Run()
+	}()
+
+	ls.Emit("init")
+	ls.Wait("start")
+	ls.Emit("result")
+	ls.Wait("done")
+	if err := <-done; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestChain_Reuse(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	handshake := ls.Fluent().Emit("hello").Wait("ack")
+	full := handshake.Then(ls.Fluent().Emit("bye"))
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			ls.Wait("hello")
+			ls.Emit("ack")
+		}()
+		if err := handshake.Run(); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	go func() {
+		ls.Wait("hello")
+		ls.Emit("ack")
+		ls.Wait("bye")
+	}()
+	if err := full.Run(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestChain_Error(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithTimeout(50*time.Millisecond))
+
+	err := ls.Fluent().Wait("x").Emit("y").Run()
+	var lerr *lockstep.LockStepError
+	if !errors.As(err, &lerr) {
+		t.Fatalf("Expected *LockStepError, got %v", err)
+	}
+	expectEqual(t, lockstep.OpWait, lerr.Op)
+	expectEqual(t, 0, ls.PendingCount())
+}
@@ -0,0 +1,55 @@
+package lockstep
+
+import (
+	"slices"
+	"strings"
+)
+
+// Group coordinates multiple LockStep instances, e.g. one per subsystem in an
+// integration test. Create one using [NewGroup].
+type Group struct {
+	ls []*LockStep
+}
+
+// NewGroup creates a Group with the provided LockStep instances.
+func NewGroup(ls ...*LockStep) *Group {
+	return &Group{ls: ls}
+}
+
+// WaitAll blocks until none of the LockStep instances in the group has Wait
+// operations in progress. Each instance is given its own timeout, and a
+// timeout is reported by the instance that timed out.
+//
+//	g := lockstep.NewGroup(db, cache)
+//	defer g.WaitAll()
+func (g *Group) WaitAll() {
+	for _, l := range g.ls {
+		l.t.Helper()
+		if err := l.Drain(l.opTimeout(l.Pending()...)); err != nil {
+			l.fail(newError(
+				OpWait, strings.Join(l.Pending(), ", "), "timeout",
+				"Group: %v", err))
+			return
+		}
+	}
+}
+
+// History returns the events recorded by all the LockStep instances in the
+// group, in chronological order.
+func (g *Group) History() []Event {
+	var h []Event
+	for _, l := range g.ls {
+		h = append(h, l.History()...)
+	}
+	slices.SortStableFunc(h, func(a, b Event) int {
+		return a.Time.Compare(b.Time)
+	})
+	return h
+}
+
+// Cancel calls [LockStep.Cancel] on all the LockStep instances in the group.
+func (g *Group) Cancel(reason string) {
+	for _, l := range g.ls {
+		l.Cancel(reason)
+	}
+}
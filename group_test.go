@@ -0,0 +1,83 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestGroup_WaitAll(t *testing.T) {
+	t.Parallel()
+
+	a := lockstep.New(t)
+	b := lockstep.New(t)
+	g := lockstep.NewGroup(a, b)
+
+	go func() {
+		a.Wait("x")
+	}()
+	go func() {
+		b.Wait("y")
+	}()
+	for a.PendingCount() == 0 || b.PendingCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		a.Emit("x")
+		b.Emit("y")
+	}()
+
+	g.WaitAll()
+	expectEqual(t, 0, a.PendingCount())
+	expectEqual(t, 0, b.PendingCount())
+}
+
+func TestGroup_WaitAllTimeout(t *testing.T) {
+	t.Parallel()
+
+	a := lockstep.New(t)
+	b := lockstep.New(&PanicFailer{T: t})
+	g := lockstep.NewGroup(a, b)
+
+	go func() {
+		b.Wait("y")
+	}()
+	for b.PendingCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Only affects WaitAll; the Wait above already started.
+	b.SetTimeout(100 * time.Millisecond)
+
+	expectFail(t, func() {
+		g.WaitAll()
+	})
+
+	b.Emit("y")
+}
+
+func TestGroup_History(t *testing.T) {
+	t.Parallel()
+
+	a := lockstep.New(t)
+	b := lockstep.New(t)
+	g := lockstep.NewGroup(a, b)
+
+	go func() {
+		a.Emit("x")
+		b.Emit("y")
+	}()
+	a.Wait("x")
+	b.Wait("y")
+
+	h := g.History()
+	expectEqual(t, 6, len(h))
+	for i := 1; i < len(h); i++ {
+		if h[i].Time.Before(h[i-1].Time) {
+			t.Fatalf("History is not sorted: %v", h)
+		}
+	}
+}
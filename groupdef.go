@@ -0,0 +1,56 @@
+package lockstep
+
+import "slices"
+
+// DefineGroup defines a named group of messages, which can be used with
+// WaitGroup and EmitGroup. Groups are configuration: they are preserved by
+// Reset and copied by Clone. Like SetTimeout, DefineGroup should be called
+// before the LockStep instance is used.
+//
+//	ls.DefineGroup("startup", "db-ready", "cache-ready", "http-ready")
+//	// ...
+//	ls.WaitGroup("startup")
+func (l *LockStep) DefineGroup(name string, ms ...string) {
+	if l.groups == nil {
+		l.groups = make(map[string][]string)
+	}
+	l.groups[name] = slices.Clone(ms)
+}
+
+// WaitGroup waits for all the messages in the group name, as if Wait was
+// called with all of them.
+func (l *LockStep) WaitGroup(name string) {
+	l.t.Helper()
+
+	ms, ok := l.group(name)
+	if !ok {
+		return
+	}
+	l.Wait(ms...)
+}
+
+// EmitGroup emits all the messages in the group name, in order, as if Emit
+// was called for each one of them.
+func (l *LockStep) EmitGroup(name string) {
+	l.t.Helper()
+
+	ms, ok := l.group(name)
+	if !ok {
+		return
+	}
+	for _, m := range ms {
+		l.Emit(m)
+	}
+}
+
+// group returns the messages in the group name. It fails if the group is not
+// defined.
+func (l *LockStep) group(name string) ([]string, bool) {
+	l.t.Helper()
+
+	ms, ok := l.groups[name]
+	if !ok {
+		l.fail(newError("group", name, "undefined group", "Undefined group %v", name))
+	}
+	return ms, ok
+}
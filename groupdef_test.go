@@ -0,0 +1,36 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_DefineGroup(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+	ls.DefineGroup("startup", "db-ready", "cache-ready")
+
+	go ls.EmitGroup("startup")
+	ls.WaitGroup("startup")
+
+	ls.Reset()
+	go func() {
+		ls.Emit("cache-ready")
+		ls.Emit("db-ready")
+	}()
+	ls.WaitGroup("startup")
+
+	clone := ls.Clone(&PanicFailer{T: t})
+	go clone.EmitGroup("startup")
+	clone.Wait("db-ready", "cache-ready")
+
+	expectFail(t, func() {
+		ls.WaitGroup("shutdown")
+	})
+	expectFail(t, func() {
+		ls.EmitGroup("shutdown")
+	})
+}
@@ -0,0 +1,266 @@
+package lockstep
+
+import (
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Op identifies the kind of an [Event].
+type Op string
+
+const (
+	// OpEmit is recorded when an Emit operation starts.
+	OpEmit Op = "emit"
+	// OpWait is recorded when a Wait operation registers a message.
+	OpWait Op = "wait"
+	// OpMatch is recorded when an Emit operation is matched with a Wait
+	// operation.
+	OpMatch Op = "match"
+	// OpTimeout is recorded when an Emit or Wait operation fails because its
+	// timeout expired or its context was done.
+	OpTimeout Op = "timeout"
+)
+
+// Event is an entry in the history of a [LockStep] instance.
+type Event struct {
+	Op      Op
+	Message string
+
+	// Goroutine is the ID of the goroutine that caused the event.
+	Goroutine uint64
+
+	// Time is when the event happened.
+	Time time.Time
+
+	// Duration is zero for OpEmit and OpWait events. For OpMatch events, it is
+	// the time elapsed between the start of the earliest of the matched Emit
+	// and Wait operations and the match. For OpTimeout events, it is the time
+	// elapsed since the operation started.
+	Duration time.Duration
+}
+
+// History returns all the events recorded by the LockStep instance, in
+// chronological order.
+func (l *LockStep) History() []Event {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return slices.Clone(l.history)
+}
+
+// MessageLatency returns the Duration of the first OpMatch event for the
+// message m, i.e. how long it took for m to be matched. ok is false if m has
+// not been matched yet.
+func (l *LockStep) MessageLatency(m string) (latency time.Duration, ok bool) {
+	m = l.qualify(m)
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	for _, e := range l.history {
+		if e.Op == OpMatch && e.Message == m {
+			return e.Duration, true
+		}
+	}
+
+	return 0, false
+}
+
+// AssertEmitted reports, using t.Errorf, each of the messages in ms for which
+// no Emit operation was recorded in the history.
+func (l *LockStep) AssertEmitted(ms ...string) {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	emitted := l.emitted()
+	for _, m := range ms {
+		if !emitted[m] {
+			l.t.Errorf("Expected emit of %v", m)
+		}
+	}
+}
+
+// AssertNotEmitted reports, using t.Errorf, each of the messages in ms for
+// which an Emit operation was recorded in the history.
+func (l *LockStep) AssertNotEmitted(ms ...string) {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	emitted := l.emitted()
+	for _, m := range ms {
+		if emitted[m] {
+			l.t.Errorf("Unexpected emit of %v", m)
+		}
+	}
+}
+
+// AssertOrder reports, using t.Errorf, if the messages in ms were not emitted
+// in the order given, according to the history. For each message, only the
+// first Emit operation is considered. Messages that were not emitted at all
+// are also reported.
+func (l *LockStep) AssertOrder(ms ...string) {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	l.mu.RLock()
+	first := make(map[string]int)
+	for i, e := range l.history {
+		if _, ok := first[e.Message]; !ok && e.Op == OpEmit {
+			first[e.Message] = i
+		}
+	}
+	l.mu.RUnlock()
+
+	for i, m := range ms {
+		pos, ok := first[m]
+		if !ok {
+			l.t.Errorf("Expected emit of %v", m)
+			continue
+		}
+		if i == 0 {
+			continue
+		}
+		if prev, ok := first[ms[i-1]]; ok && prev > pos {
+			l.t.Errorf("Expected emit of %v before %v", ms[i-1], m)
+		}
+	}
+}
+
+// MeasureBetween returns the time elapsed between the first Emit operations
+// for start and end, according to the history. It fails if either message was
+// not emitted.
+//
+//	elapsed := ls.MeasureBetween("request-sent", "response-received")
+func (l *LockStep) MeasureBetween(start, end string) time.Duration {
+	l.t.Helper()
+
+	start, end = l.qualify(start), l.qualify(end)
+
+	d, err := l.measureBetween(start, end)
+	if err != nil {
+		l.fail(err)
+	}
+	return d
+}
+
+// VerifyWithin fails if the time elapsed between the first Emit operations for
+// start and end, according to the history, is negative or greater than
+// window. The failure includes the history of the LockStep instance.
+//
+//	ls.VerifyWithin("request-sent", "response-received", 100*time.Millisecond)
+func (l *LockStep) VerifyWithin(start, end string, window time.Duration) {
+	l.t.Helper()
+
+	start, end = l.qualify(start), l.qualify(end)
+
+	d, err := l.measureBetween(start, end)
+	if err != nil {
+		l.fail(err)
+		return
+	}
+	if d < 0 || d > window {
+		l.fail(newError(
+			"verify", start+", "+end, "outside window",
+			"Expected %v to %v within %v, actual %v\nHistory:\n%v",
+			start, end, window, d, formatHistory(l.History())))
+	}
+}
+
+// measureBetween is like MeasureBetween, but it returns failures.
+func (l *LockStep) measureBetween(start, end string) (time.Duration, *LockStepError) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var startTime, endTime time.Time
+	for _, e := range l.history {
+		if e.Op != OpEmit {
+			continue
+		}
+		if e.Message == start && startTime.IsZero() {
+			startTime = e.Time
+		}
+		if e.Message == end && endTime.IsZero() {
+			endTime = e.Time
+		}
+	}
+
+	for _, m := range []struct {
+		name string
+		time time.Time
+	}{{start, startTime}, {end, endTime}} {
+		if m.time.IsZero() {
+			return 0, newError("measure", m.name, "not emitted", "Expected emit of %v", m.name)
+		}
+	}
+
+	return endTime.Sub(startTime), nil
+}
+
+// formatHistory formats events, one per line.
+func formatHistory(events []Event) string {
+	var b strings.Builder
+	for _, e := range events {
+		fmt.Fprintf(&b, "  %v %v %v (goroutine %v)",
+			e.Time.Format("15:04:05.000000"), e.Op, e.Message, e.Goroutine)
+		if e.Duration != 0 {
+			fmt.Fprintf(&b, " after %v", e.Duration)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// emitted returns the set of messages with OpEmit events in the history.
+func (l *LockStep) emitted() map[string]bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	emitted := make(map[string]bool)
+	for _, e := range l.history {
+		if e.Op == OpEmit {
+			emitted[e.Message] = true
+		}
+	}
+	return emitted
+}
+
+func (l *LockStep) recordWithLock(op Op, m string, d time.Duration) {
+	e := Event{
+		Op:        op,
+		Message:   m,
+		Goroutine: goroutineID(),
+		Time:      time.Now(),
+		Duration:  d,
+	}
+	l.history = append(l.history, e)
+	for _, r := range l.recordings {
+		r.add(e)
+	}
+	l.stats.add(e)
+	if l.metrics != nil {
+		l.metrics.add(e)
+	}
+	l.logEvent(e)
+}
+
+// recordTimeoutWithLock records the timeout of the operation op for m, which
+// started elapsed ago, calls the OnTimeout hooks and counts it for
+// WithAbortAfter. l.mu must be held.
+func (l *LockStep) recordTimeoutWithLock(op Op, m string, elapsed time.Duration) {
+	l.recordWithLock(OpTimeout, m, elapsed)
+	l.hooks.timedOut(op, m, elapsed)
+	l.countTimeoutWithLock()
+}
+
+// goroutineID returns the ID of the calling goroutine, as reported by
+// runtime.Stack.
+func goroutineID() uint64 {
+	var buf [64]byte
+	return stackGoroutineID(string(buf[:runtime.Stack(buf[:], false)]))
+}
@@ -0,0 +1,177 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_History(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Wait("x")
+	}()
+	for ls.PendingCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	ls.Emit("x")
+	<-done
+
+	h := ls.History()
+	expectEqual(t, 3, len(h))
+	expectEqual(t, lockstep.OpWait, h[0].Op)
+	expectEqual(t, lockstep.OpEmit, h[1].Op)
+	expectEqual(t, lockstep.OpMatch, h[2].Op)
+	for _, e := range h {
+		expectEqual(t, "x", e.Message)
+	}
+	if h[0].Goroutine == h[1].Goroutine {
+		t.Fatalf("Expected wait and emit on different goroutines")
+	}
+	if h[2].Duration < 10*time.Millisecond {
+		t.Fatalf("Expected match latency to include the wait, got %v", h[2].Duration)
+	}
+
+	ls.Reset()
+	expectEqual(t, 0, len(ls.History()))
+}
+
+func TestLockStep_HistoryTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+	ls.SetTimeout(100 * time.Millisecond)
+
+	expectFail(t, func() {
+		ls.Wait("x")
+	})
+
+	h := ls.History()
+	expectEqual(t, 2, len(h))
+	expectEqual(t, lockstep.OpWait, h[0].Op)
+	expectEqual(t, lockstep.OpTimeout, h[1].Op)
+	expectEqual(t, "x", h[1].Message)
+}
+
+func TestLockStep_MessageLatency(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	_, ok := ls.MessageLatency("x")
+	expectEqual(t, false, ok)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+
+	d, ok := ls.MessageLatency("x")
+	expectEqual(t, true, ok)
+	if d < 100*time.Millisecond || d > time.Second {
+		t.Fatalf("Unexpected latency %v", d)
+	}
+}
+
+func TestLockStep_AssertEmitted(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r)
+
+	go func() {
+		ls.Emit("x")
+		ls.Emit("y")
+	}()
+	ls.Wait("x", "y")
+
+	ls.AssertEmitted("x", "y")
+	ls.AssertNotEmitted("z")
+	expectEqual(t, 0, len(r.Errors()))
+
+	ls.AssertEmitted("x", "z")
+	ls.AssertNotEmitted("y", "w")
+	errs := r.Errors()
+	expectEqual(t, 2, len(errs))
+	expectEqual(t, "Expected emit of z", errs[0])
+	expectEqual(t, "Unexpected emit of y", errs[1])
+}
+
+func TestLockStep_AssertOrder(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r)
+
+	go func() {
+		ls.Emit("a")
+		ls.Emit("b")
+		ls.Emit("c")
+	}()
+	ls.Wait("a", "b", "c")
+
+	ls.AssertOrder("a", "b", "c")
+	ls.AssertOrder("a", "c")
+	expectEqual(t, 0, len(r.Errors()))
+
+	ls.AssertOrder("b", "a", "d")
+	errs := r.Errors()
+	expectEqual(t, 2, len(errs))
+	expectEqual(t, "Expected emit of b before a", errs[0])
+	expectEqual(t, "Expected emit of d", errs[1])
+}
+
+func TestLockStep_MeasureBetween(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	go func() {
+		ls.Emit("request-sent")
+		time.Sleep(20 * time.Millisecond)
+		ls.Emit("response-received")
+	}()
+	ls.Wait("request-sent")
+	ls.Wait("response-received")
+
+	d := ls.MeasureBetween("request-sent", "response-received")
+	if d < 20*time.Millisecond || d > time.Second {
+		t.Fatalf("Unexpected duration %v", d)
+	}
+
+	expectFail(t, func() {
+		ls.MeasureBetween("request-sent", "never")
+	})
+}
+
+func TestLockStep_VerifyWithin(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	go func() {
+		ls.Emit("start")
+		time.Sleep(20 * time.Millisecond)
+		ls.Emit("end")
+	}()
+	ls.Wait("start")
+	ls.Wait("end")
+
+	ls.VerifyWithin("start", "end", time.Second)
+	expectFail(t, func() {
+		ls.VerifyWithin("start", "end", time.Millisecond)
+	})
+	expectFail(t, func() {
+		ls.VerifyWithin("end", "start", time.Second)
+	})
+	expectFail(t, func() {
+		ls.VerifyWithin("start", "never", time.Second)
+	})
+}
@@ -0,0 +1,115 @@
+package lockstep
+
+import (
+	"slices"
+	"time"
+)
+
+// hooks holds the callbacks registered with OnEmit and similar methods. It is
+// protected by l.mu.
+type hooks struct {
+	onEmit    []func(m string, t time.Time)
+	onMatch   []func(m string, waitTime, emitTime time.Time)
+	onTimeout []func(op, m string, elapsed time.Duration)
+
+	emitInterceptors []EmitInterceptor
+	waitInterceptors []WaitInterceptor
+}
+
+// clone returns a copy of h that does not share its slices with h.
+func (h *hooks) clone() hooks {
+	return hooks{
+		onEmit:           slices.Clone(h.onEmit),
+		onMatch:          slices.Clone(h.onMatch),
+		onTimeout:        slices.Clone(h.onTimeout),
+		emitInterceptors: slices.Clone(h.emitInterceptors),
+		waitInterceptors: slices.Clone(h.waitInterceptors),
+	}
+}
+
+// OnEmit registers hook to be called every time an Emit operation is matched
+// with a Wait operation, with the message and the time of the match. Hooks are
+// called synchronously, with the lock of the LockStep instance held, in the
+// order they were registered. Messages are passed with their full names (see
+// [LockStep.Child]).
+//
+// Hooks must not call back into the LockStep instance, or they will deadlock.
+//
+//	ls.OnEmit(func(m string, t time.Time) {
+//		metrics.Record(m, t)
+//	})
+func (l *LockStep) OnEmit(hook func(m string, t time.Time)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks.onEmit = append(l.hooks.onEmit, hook)
+}
+
+// OnMatch registers hook to be called every time an Emit operation is matched
+// with a Wait operation, with the message and the times when the Wait and Emit
+// operations started, e.g. to record latencies. emitTime is the zero time for
+// matches made by TryWait, since the Emit operation may have started before
+// it could be tracked. Hooks are called like those registered with
+// [LockStep.OnEmit], and must not call back into the LockStep instance either.
+//
+//	ls.OnMatch(func(m string, waitTime, emitTime time.Time) {
+//		latency.Observe(m, time.Since(waitTime))
+//	})
+func (l *LockStep) OnMatch(hook func(m string, waitTime, emitTime time.Time)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks.onMatch = append(l.hooks.onMatch, hook)
+}
+
+// OnTimeout registers hook to be called every time an Emit or Wait operation
+// times out, or fails because its context is done or the LockStep instance
+// was cancelled. op is "emit" or "wait", and elapsed is the time since the
+// operation started. Hooks are called before the failure is reported, e.g. to
+// dump additional debugging information. They are called like those
+// registered with [LockStep.OnEmit], so they should complete quickly or spawn
+// their own goroutines.
+//
+//	ls.OnTimeout(func(op, m string, elapsed time.Duration) {
+//		fmt.Fprintf(os.Stderr, "%v %v timed out after %v\n", op, m, elapsed)
+//	})
+func (l *LockStep) OnTimeout(hook func(op, m string, elapsed time.Duration)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks.onTimeout = append(l.hooks.onTimeout, hook)
+}
+
+// RemoveAllHooks unregisters all the hooks registered with OnEmit, OnMatch
+// and OnTimeout, as well as all the interceptors.
+func (l *LockStep) RemoveAllHooks() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = hooks{}
+}
+
+// emitted calls the OnEmit hooks for m. l.mu must be held.
+func (h *hooks) emitted(m string) {
+	if len(h.onEmit) == 0 {
+		return
+	}
+	now := time.Now()
+	for _, hook := range h.onEmit {
+		hook(m, now)
+	}
+}
+
+// matched calls the OnMatch hooks for m. l.mu must be held.
+func (h *hooks) matched(m string, waitTime, emitTime time.Time) {
+	for _, hook := range h.onMatch {
+		hook(m, waitTime, emitTime)
+	}
+}
+
+// timedOut calls the OnTimeout hooks. l.mu must be held.
+func (h *hooks) timedOut(op Op, m string, elapsed time.Duration) {
+	for _, hook := range h.onTimeout {
+		hook(string(op), m, elapsed)
+	}
+}
@@ -0,0 +1,84 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_OnEmit(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	var calls []string
+	ls.OnEmit(func(m string, _ time.Time) {
+		calls = append(calls, "1:"+m)
+	})
+	ls.OnEmit(func(m string, _ time.Time) {
+		calls = append(calls, "2:"+m)
+	})
+
+	// No Wait operation, no match.
+	ls.TryEmit("x")
+
+	go ls.Emit("x")
+	ls.Wait("x")
+
+	ls.RemoveAllHooks()
+	go ls.Emit("y")
+	ls.Wait("y")
+
+	expectEqual(t, 2, len(calls))
+	expectEqual(t, "1:x", calls[0])
+	expectEqual(t, "2:x", calls[1])
+}
+
+func TestLockStep_OnMatch(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	var waitTime, emitTime time.Time
+	ls.OnMatch(func(m string, w, e time.Time) {
+		expectEqual(t, "x", m)
+		waitTime, emitTime = w, e
+	})
+
+	begin := time.Now()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+
+	if waitTime.Before(begin) || emitTime.Sub(waitTime) < 50*time.Millisecond {
+		t.Fatalf("Unexpected times: wait %v, emit %v", waitTime.Sub(begin), emitTime.Sub(begin))
+	}
+}
+
+func TestLockStep_OnTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(50*time.Millisecond))
+
+	var calls []string
+	ls.OnTimeout(func(op, m string, elapsed time.Duration) {
+		if elapsed < 50*time.Millisecond {
+			t.Errorf("Unexpected elapsed time: %v", elapsed)
+		}
+		calls = append(calls, op+" "+m)
+	})
+
+	expectFail(t, func() {
+		ls.Emit("x")
+	})
+	expectFail(t, func() {
+		ls.Wait("y")
+	})
+
+	expectEqual(t, 2, len(calls))
+	expectEqual(t, "emit x", calls[0])
+	expectEqual(t, "wait y", calls[1])
+}
@@ -0,0 +1,77 @@
+package lockstep
+
+// EmitInterceptor intercepts Emit operations. It receives the message m and
+// the function next, which performs the Emit operation, or calls the next
+// interceptor. An interceptor can do work before or after calling next, call
+// next with a different message, or not call it at all to drop the operation.
+type EmitInterceptor func(m string, next func(m string))
+
+// WaitInterceptor intercepts Wait operations, like [EmitInterceptor] does for
+// Emit operations. It receives all the messages of the operation.
+type WaitInterceptor func(ms []string, next func(ms []string))
+
+// AddEmitInterceptor adds i to the interceptors of the Emit operations of l.
+// Interceptors are called in the order they were added, each one wrapping the
+// ones added after it. They apply to Emit, EmitCtx, EmitWithin, EmitE,
+// EmitAfter, EmitValue, EmitN, Broadcast, Once, TryEmit and BufferedEmit, and
+// receive messages with their full names (see [LockStep.Child]). EmitN calls
+// the interceptors once for all its n operations. A dropped Emit operation
+// succeeds without emitting anything: TryEmit returns true.
+//
+//	// Simulate a slow Emit.
+//	ls.AddEmitInterceptor(func(m string, next func(m string)) {
+//		time.Sleep(10 * time.Millisecond)
+//		next(m)
+//	})
+//
+// Interceptors are removed by [LockStep.RemoveAllHooks].
+func (l *LockStep) AddEmitInterceptor(i EmitInterceptor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks.emitInterceptors = append(l.hooks.emitInterceptors, i)
+}
+
+// AddWaitInterceptor adds i to the interceptors of the Wait operations of l.
+// Interceptors are called like those added with [LockStep.AddEmitInterceptor].
+// They apply to Wait, WaitCtx, WaitWithin, WaitE, WaitN, WaitAny and the Wait
+// operation started by CountDown. WaitN and CountDown call the interceptors
+// once for all their n operations. A dropped Wait operation succeeds without
+// waiting: WaitAny returns "". The other Wait operations, such as TryWait,
+// WaitValue and the pattern waits, are not intercepted.
+func (l *LockStep) AddWaitInterceptor(i WaitInterceptor) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks.waitInterceptors = append(l.hooks.waitInterceptors, i)
+}
+
+// interceptEmit calls emit with m through the emit interceptors.
+func (l *LockStep) interceptEmit(m string, emit func(m string)) {
+	l.mu.RLock()
+	is := l.hooks.emitInterceptors
+	l.mu.RUnlock()
+
+	for k := len(is) - 1; k >= 0; k-- {
+		i, next := is[k], emit
+		emit = func(m string) {
+			i(m, next)
+		}
+	}
+	emit(m)
+}
+
+// interceptWait calls wait with ms through the wait interceptors.
+func (l *LockStep) interceptWait(ms []string, wait func(ms []string)) {
+	l.mu.RLock()
+	is := l.hooks.waitInterceptors
+	l.mu.RUnlock()
+
+	for k := len(is) - 1; k >= 0; k-- {
+		i, next := is[k], wait
+		wait = func(ms []string) {
+			i(ms, next)
+		}
+	}
+	wait(ms)
+}
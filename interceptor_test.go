@@ -0,0 +1,115 @@
+package lockstep_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_Interceptors(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	var calls []string
+	ls.AddEmitInterceptor(func(m string, next func(m string)) {
+		calls = append(calls, "outer "+m)
+		next(m)
+	})
+	ls.AddEmitInterceptor(func(m string, next func(m string)) {
+		calls = append(calls, "inner "+m)
+		switch m {
+		case "drop":
+		case "x":
+			next("y")
+		default:
+			next(m)
+		}
+	})
+	ls.AddWaitInterceptor(func(ms []string, next func(ms []string)) {
+		if ms[0] == "x" {
+			ms = []string{"y"}
+		}
+		next(ms)
+	})
+
+	// Dropped, so it doesn't block.
+	ls.Emit("drop")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+	<-done
+
+	expectEqual(t, 4, len(calls))
+	expectEqual(t, "outer drop", calls[0])
+	expectEqual(t, "inner drop", calls[1])
+	expectEqual(t, "outer x", calls[2])
+	expectEqual(t, "inner x", calls[3])
+
+	ls.AssertEmitted("y")
+	ls.AssertNotEmitted("x", "drop")
+
+	ls.RemoveAllHooks()
+	go ls.Emit("x")
+	ls.Wait("x")
+	expectEqual(t, 4, len(calls))
+}
+
+func TestLockStep_Interceptors_Operations(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	var mu sync.Mutex
+	var emits, waits []string
+	ls.AddEmitInterceptor(func(m string, next func(m string)) {
+		mu.Lock()
+		emits = append(emits, m)
+		mu.Unlock()
+		if m != "drop" {
+			next(m)
+		}
+	})
+	ls.AddWaitInterceptor(func(ms []string, next func(ms []string)) {
+		mu.Lock()
+		waits = append(waits, strings.Join(ms, ","))
+		mu.Unlock()
+		if ms[0] != "drop" {
+			next(ms)
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.EmitN("n", 2)
+		ls.Broadcast("b")
+	}()
+	ls.WaitN("n", 2)
+	expectEqual(t, "b", ls.WaitAny("b", "c"))
+	<-done
+
+	ls.Once("once")
+	expectEqual(t, false, ls.TryEmit("try"))
+	ls.BufferedEmit("buf")
+	ls.Wait("buf")
+
+	// Dropped operations succeed without emitting or waiting.
+	expectEqual(t, true, ls.TryEmit("drop"))
+	expectEqual(t, "", ls.WaitAny("drop", "other"))
+
+	// Not intercepted.
+	ls.TryWait("once")
+	ls.WaitPrefix("on")
+
+	mu.Lock()
+	defer mu.Unlock()
+	expectEqual(t, "n,b,once,try,buf,drop", strings.Join(emits, ","))
+	expectEqual(t, "n;b,c;buf;drop,other", strings.Join(waits, ";"))
+}
@@ -0,0 +1,100 @@
+package lockstep
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// leakGracePeriod is how long checkLeaks gives goroutines to exit before
+// reporting them.
+const leakGracePeriod = time.Second
+
+// WithLeakDetection configures the LockStep to register a t.Cleanup function
+// that reports, using t.Errorf, any goroutines started after the LockStep was
+// created that are still running when the test finishes, e.g. because they are
+// blocked on a Wait operation that was never fulfilled. Goroutines internal to
+// LockStep are not reported.
+//
+// Since all goroutines in the test binary are considered, leak detection
+// should not be used in tests that run in parallel with other tests.
+func WithLeakDetection(v bool) Option {
+	return func(l *LockStep) {
+		l.leakDetection = v
+	}
+}
+
+// checkLeaks reports the goroutines that are not in before, after giving them
+// leakGracePeriod to exit.
+func (l *LockStep) checkLeaks(before map[uint64]bool) {
+	deadline := time.Now().Add(leakGracePeriod)
+	for {
+		leaked := leakedGoroutines(before)
+		if len(leaked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			for _, stack := range leaked {
+				l.t.Errorf("Leaked goroutine:\n%v", stack)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// leakedGoroutines returns the stacks of the goroutines that are not in
+// before, other than the calling goroutine and LockStep internal goroutines.
+func leakedGoroutines(before map[uint64]bool) []string {
+	self := goroutineID()
+	var leaked []string
+	for _, stack := range goroutineStacks() {
+		id := stackGoroutineID(stack)
+		if before[id] || id == self || isInternalGoroutine(stack) {
+			continue
+		}
+		leaked = append(leaked, stack)
+	}
+	return leaked
+}
+
+// isInternalGoroutine returns true if stack belongs to a goroutine started
+// by LockStep to implement AbortOnTestFailure.
+func isInternalGoroutine(stack string) bool {
+	return strings.Contains(stack, packagePrefix+"(*LockStep).abortOnTestFailure(")
+}
+
+// goroutineIDs returns the IDs of all the goroutines.
+func goroutineIDs() map[uint64]bool {
+	ids := make(map[uint64]bool)
+	for _, stack := range goroutineStacks() {
+		ids[stackGoroutineID(stack)] = true
+	}
+	return ids
+}
+
+// goroutineStacks returns the stacks of all the goroutines, as reported by
+// runtime.Stack.
+func goroutineStacks() []string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	return strings.Split(strings.TrimSpace(string(buf)), "\n\n")
+}
+
+// stackGoroutineID returns the ID of the goroutine of stack.
+func stackGoroutineID(stack string) uint64 {
+	s := strings.TrimPrefix(stack, "goroutine ")
+	if i := strings.IndexByte(s, ' '); i >= 0 {
+		s = s[:i]
+	}
+	id, _ := strconv.ParseUint(s, 10, 64)
+	return id
+}
@@ -0,0 +1,57 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+// TestWithLeakDetection is not parallel because goroutines from other tests
+// would be reported as leaked.
+func TestWithLeakDetection(t *testing.T) {
+	var r *Recorder
+	var ls *lockstep.LockStep
+	t.Run("sub", func(t *testing.T) {
+		r = &Recorder{T: t}
+		ls = lockstep.New(r, lockstep.WithLeakDetection(true))
+
+		go func() {
+			ls.Wait("x")
+		}()
+		// Exits during the grace period.
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+		}()
+		for ls.PendingCount() == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	errs := r.Errors()
+	expectEqual(t, 1, len(errs))
+	if !strings.HasPrefix(errs[0], "Leaked goroutine:\ngoroutine ") ||
+		!strings.Contains(errs[0], "TestWithLeakDetection") {
+		t.Fatalf("Unexpected error: %q", errs[0])
+	}
+
+	ls.Emit("x")
+}
+
+// TestWithLeakDetection_Bridge is not parallel because goroutines from other
+// tests would be reported as leaked.
+func TestWithLeakDetection_Bridge(t *testing.T) {
+	var r *Recorder
+	t.Run("sub", func(t *testing.T) {
+		r = &Recorder{T: t}
+		ls := lockstep.New(r, lockstep.WithLeakDetection(true))
+
+		// The goroutines started by LockStep stop when the test completes.
+		ls.WrapChan("x", make(chan struct{}))
+		ls.AsChan("y")
+		ls.AsContext("z")
+	})
+
+	expectEqual(t, 0, len(r.Errors()))
+}
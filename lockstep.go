@@ -44,8 +44,11 @@ package lockstep
 
 import (
 	"context"
-	"iter"
+	"fmt"
+	"io"
+	"log/slog"
 	"maps"
+	"math/rand"
 	"slices"
 	"strings"
 	"sync"
@@ -58,66 +61,796 @@ const DefaultTimeout = 10 * time.Second
 
 // Lockstep is a testing primitive.
 type LockStep struct {
-	t       testing.TB
-	verbose bool
-	timeout time.Duration
+	*core
 
-	mu      sync.Mutex
+	// prefix is prepended to all the messages of the instance. It is only set
+	// for instances created with Child, which share the core of their parent.
+	prefix string
+}
+
+// core holds the configuration and state of a LockStep instance, shared with
+// its children.
+type core struct {
+	t testing.TB
+
+	// ctx is done when the instance is no longer in use, i.e. when the test
+	// completes. It stops the background goroutines started by the instance.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Configuration. Remember to update Clone when adding fields.
+	name              string
+	verbose           bool
+	timeout           time.Duration
+	timeouts          map[string]time.Duration
+	softTimeout       time.Duration
+	failureMode       FailureMode
+	failureFunc       func(format string, args ...any)
+	cleanup           bool
+	deadlineFromTest  bool
+	doubleEmit        DuplicatePolicy
+	doubleWait        DuplicatePolicy
+	bufferSize        int
+	groups            map[string][]string
+	color             *bool
+	separator         string
+	leakDetection     bool
+	abortOnFailure    bool
+	tracer            Tracer
+	registerer        MetricsRegisterer
+	delayMin          time.Duration
+	delayMax          time.Duration
+	seed              int64
+	seeded            bool
+	maxPermutations   int
+	deadlockDetection bool
+	abortAfter        int
+
+	// The verbose log destination. It is protected by outputMu because it is
+	// used with or without mu held.
+	outputMu sync.Mutex
+	output   io.Writer
+	logger   slog.Handler
+
+	// mu protects the state below. Methods that only inspect the state, such
+	// as Pending and History, take it for reading.
+	mu      sync.RWMutex
 	cv      *sync.Cond
-	waiting map[string]bool
+	waiting map[string]*waiter
+
+	// waitQueue holds, for each message, the waiters registered while
+	// another one was already waiting for it, in FIFO order. The first one
+	// takes the place of the current waiter once it is done with the message.
+	// See WithDoubleWaitPolicy.
+	waitQueue map[string][]*waiter
+
+	// patterns holds the waiters that match messages using a function
+	// instead of by name, in FIFO order. See WaitPrefix.
+	patterns []*waiter
+
+	// emitting counts the Emit operations currently blocked on each message.
+	// consumed counts those that were satisfied by TryWait but have not yet
+	// returned.
+	emitting map[string]int
+	consumed map[string]int
+
+	// emitQueue holds the blocked Emit operations for each message, in the
+	// order they started, so that they are matched in FIFO order.
+	emitQueue map[string][]uint64
+	emitSeq   uint64
+
+	// emits counts all the Emit operations started for each message.
+	emits map[string]int
+
+	// latched holds the messages latched by Once.
+	latched map[string]bool
+
+	// buffered counts the messages stored by BufferedEmit.
+	buffered map[string]int
+
+	// rendezvous holds the Rendezvous operations waiting for a counterpart.
+	rendezvous map[string]*rendezvous
+
+	// sequence is the sequence declared by ExpectSequence, if any.
+	sequence *expectedSequence
+
+	// bridges holds the instances that Emit operations are forwarded to,
+	// per message. See Bridge.
+	bridges map[string][]bridge
+
+	history []Event
+	stats   stats
+	// metrics is set if WithPrometheusRegistry was used.
+	metrics *metrics
+
+	// hooks holds the callbacks registered with OnEmit and similar methods.
+	hooks hooks
+
+	// cancelReason is set by Cancel. It is not protected by mu so that
+	// failures can be reported with or without mu held.
+	cancelReason atomic.Pointer[string]
+
+	// timeoutCount is the number of timeouts, counted for WithAbortAfter.
+	timeoutCount atomic.Int64
+
+	// recordings holds the Recordings in progress. See Record.
+	recordings []*Recording
+
+	// delayRand is the random source for VaryDelay, guarded by delayMu.
+	delayMu   sync.Mutex
+	delayRand *rand.Rand
+
+	// ordering is the order in which messages must be emitted, set by
+	// ExploreOrderings.
+	ordering *ordering
+
+	// tracked is the number of goroutines registered for deadlock detection,
+	// and goroutines holds the IDs of those that started. blocked is how many
+	// of them blocked since the last broadcast, which incremented blockedGen.
+	// See WithDeadlockDetection.
+	tracked    int
+	goroutines map[uint64]bool
+	blocked    int
+	blockedGen uint64
+
+	// errs holds the failures accumulated in error collection mode. It is
+	// protected by errMu instead of mu because failures may be reported with
+	// or without mu held.
+	errMu   sync.Mutex
+	collect bool
+	errs    []error
 }
 
 // New creates a LockStep instance. The provided test context will be used for
-// logging and for timeout failures.
-func New(t testing.TB) *LockStep {
-	l := &LockStep{
-		t:       t,
-		timeout: DefaultTimeout,
-		waiting: make(map[string]bool),
+// logging and for timeout failures. The default timeout and verbose mode can
+// be changed for all instances with environment variables (see [EnvTimeout])
+// or flags (see [RegisterFlags]).
+//
+//	ls := lockstep.New(t, lockstep.WithTimeout(time.Second), lockstep.WithVerbose(true))
+func New(t testing.TB, opts ...Option) *LockStep {
+	timeout, verbose, err := defaults()
+	if err != nil {
+		t.Helper()
+		t.Fatalf("LockStep: %v", err)
 	}
+	l := &LockStep{core: &core{
+		t:          t,
+		timeout:    timeout,
+		verbose:    verbose,
+		separator:  DefaultSeparator,
+		doubleWait: Fail,
+		waiting:    make(map[string]*waiter),
+		waitQueue:  make(map[string][]*waiter),
+		emitting:   make(map[string]int),
+		emitQueue:  make(map[string][]uint64),
+		consumed:   make(map[string]int),
+		emits:      make(map[string]int),
+		latched:    make(map[string]bool),
+		buffered:   make(map[string]int),
+	}}
 
 	l.cv = sync.NewCond(&l.mu)
+	l.ctx, l.cancel = context.WithCancel(context.Background())
+	t.Cleanup(l.cancel)
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if l.cleanup {
+		t.Cleanup(l.checkPending)
+	}
+
+	if l.leakDetection {
+		before := goroutineIDs()
+		t.Cleanup(func() {
+			// Cancel l first, so that the goroutines that exit when l is
+			// cancelled, e.g. those started by WrapChan, are not reported.
+			l.cancel()
+			l.checkLeaks(before)
+		})
+	}
+
+	if l.abortOnFailure {
+		go l.abortOnTestFailure()
+	}
+
+	l.newDelayRand()
+
+	if l.registerer != nil {
+		l.metrics = newMetrics(l.registerer, l.name)
+	}
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			l.PrintTimeline()
+		}
+	})
 
 	return l
 }
 
+// NewNamed creates a LockStep instance with the given name. It is equivalent to
+// New(t, append(opts, WithName(name))...).
+func NewNamed(t testing.TB, name string, opts ...Option) *LockStep {
+	return New(t, append(opts, WithName(name))...)
+}
+
+// Name returns the name of the LockStep instance, as configured with
+// [WithName], or "".
+func (l *LockStep) Name() string {
+	return l.name
+}
+
+// Clone creates a new LockStep instance bound to t with the same configuration
+// as l, including the options passed to New, any settings changed afterwards
+// and the registered hooks and interceptors, but none of its state.
+func (l *LockStep) Clone(t testing.TB) *LockStep {
+	l.outputMu.Lock()
+	output, logger := l.output, l.logger
+	l.outputMu.Unlock()
+
+	l.errMu.Lock()
+	collect := l.collect
+	l.errMu.Unlock()
+
+	l.mu.RLock()
+	hooks := l.hooks.clone()
+	l.mu.RUnlock()
+
+	return New(t, func(c *LockStep) {
+		c.name = l.name
+		c.verbose = l.verbose
+		c.timeout = l.timeout
+		c.timeouts = maps.Clone(l.timeouts)
+		c.softTimeout = l.softTimeout
+		c.failureMode = l.failureMode
+		c.failureFunc = l.failureFunc
+		c.cleanup = l.cleanup
+		c.deadlineFromTest = l.deadlineFromTest
+		c.doubleEmit = l.doubleEmit
+		c.doubleWait = l.doubleWait
+		c.bufferSize = l.bufferSize
+		c.groups = maps.Clone(l.groups)
+		c.color = l.color
+		c.separator = l.separator
+		c.leakDetection = l.leakDetection
+		c.abortOnFailure = l.abortOnFailure
+		c.tracer = l.tracer
+		c.registerer = l.registerer
+		c.delayMin = l.delayMin
+		c.delayMax = l.delayMax
+		c.seed = l.seed
+		c.seeded = l.seeded
+		c.maxPermutations = l.maxPermutations
+		c.deadlockDetection = l.deadlockDetection
+		c.abortAfter = l.abortAfter
+		c.output = output
+		c.logger = logger
+		c.collect = collect
+		c.hooks = hooks
+	})
+}
+
 // SetTimeout overrides [DefaultTimeout] for Emit and Wait operations. Increase
 // the timeout when debugging.
 func (l *LockStep) SetTimeout(d time.Duration) {
 	l.timeout = d
 }
 
+// SetFailureHandler replaces the reporting of failures with a call to f. f
+// receives the same format and arguments that would otherwise have been passed
+// to t.Fatalf. If f returns, the failed operation returns immediately. A
+// failure handler takes precedence over the configured [FailureMode]. Passing
+// nil restores the default behavior.
+func (l *LockStep) SetFailureHandler(f func(format string, args ...any)) {
+	l.failureFunc = f
+}
+
+// SetPerMessageTimeout overrides the timeout for Emit and Wait operations
+// involving the message m. Operations involving multiple messages use the
+// longest of their timeouts. Like SetTimeout, it should be called before the
+// LockStep instance is used.
+func (l *LockStep) SetPerMessageTimeout(m string, d time.Duration) {
+	m = l.qualify(m)
+
+	if l.timeouts == nil {
+		l.timeouts = make(map[string]time.Duration)
+	}
+	l.timeouts[m] = d
+}
+
+// opTimeout returns the timeout for an Emit or Wait operation for ms starting
+// now.
+func (l *LockStep) opTimeout(ms ...string) time.Duration {
+	d := l.timeout
+	for i, m := range ms {
+		md, ok := l.timeouts[m]
+		if !ok {
+			md = l.timeout
+		}
+		if i == 0 || md > d {
+			d = md
+		}
+	}
+	return l.capTimeout(d)
+}
+
+// capTimeout returns the timeout d for an operation starting now, shortened if
+// needed to honor WithDeadlineFromTest.
+func (l *LockStep) capTimeout(d time.Duration) time.Duration {
+	if !l.deadlineFromTest {
+		return d
+	}
+
+	// Deadline is not part of testing.TB.
+	dt, ok := l.t.(interface{ Deadline() (time.Time, bool) })
+	if !ok {
+		return d
+	}
+	deadline, ok := dt.Deadline()
+	if !ok {
+		return d
+	}
+	if untilDeadline := time.Until(deadline); untilDeadline < d {
+		d = untilDeadline
+	}
+	return d
+}
+
 // SetVerbose configures verbose mode. If enabled, LockStep will emit detailed
 // logs using t.Logf. Useful for debugging.
 func (l *LockStep) SetVerbose(v bool) {
 	l.verbose = v
 }
 
+// Reset clears all the state of the LockStep instance while preserving its
+// configuration, so it can be reused, e.g. across sub-tests. It fails if any
+// Emit, Wait or Rendezvous operation is in progress, or if a sequence declared
+// with ExpectSequence has not been verified.
+func (l *LockStep) Reset() {
+	l.t.Helper()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.waiting) != 0 || len(l.patterns) != 0 || len(l.emitting) != 0 ||
+		len(l.rendezvous) != 0 || l.sequence != nil {
+		details := ""
+		if len(l.rendezvous) != 0 {
+			details += fmt.Sprintf(", rendezvous on [%v]", messageList(slices.Collect(maps.Keys(l.rendezvous))))
+		}
+		if l.sequence != nil {
+			details += ", sequence not verified"
+		}
+		l.fail(newError(
+			"reset", "", "operations in progress",
+			"Reset while operations are in progress: waiting for [%v], emitting [%v]%v",
+			strings.Join(l.pendingWithLock(), ", "), messageList(slices.Collect(maps.Keys(l.emitting))), details))
+		return
+	}
+
+	l.waiting = make(map[string]*waiter)
+	l.waitQueue = make(map[string][]*waiter)
+	l.emitting = make(map[string]int)
+	l.consumed = make(map[string]int)
+	l.emits = make(map[string]int)
+	l.latched = make(map[string]bool)
+	l.buffered = make(map[string]int)
+	l.rendezvous = nil
+	l.history = nil
+	l.stats.reset()
+}
+
 // Emit will emit the message m. It will block until a corresponding Wait
 // operation for m is processed.
 func (l *LockStep) Emit(m string) {
 	l.t.Helper()
+	l.EmitCtx(context.Background(), m)
+}
+
+// EmitCtx is like Emit, but it will also fail if ctx is done before the
+// corresponding Wait operation is processed. The timeout configured with
+// [LockStep.SetTimeout] still applies; whichever deadline is closer wins.
+func (l *LockStep) EmitCtx(ctx context.Context, m string) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	if err := l.emit(ctx, m, nil, l.opTimeout(m)); err != nil {
+		l.fail(err)
+	}
+}
+
+// EmitWithin is like Emit, but it uses d as the timeout instead of the one
+// configured for the LockStep instance. Use it to assert that the
+// corresponding Wait happens within a specific window.
+func (l *LockStep) EmitWithin(d time.Duration, m string) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	if err := l.emit(withTimerBound(context.Background()), m, nil, l.capTimeout(d)); err != nil {
+		l.fail(err)
+	}
+}
+
+// EmitE is like Emit, but it returns a *LockStepError on failure instead of
+// reporting it to the test. This makes it safe to use from goroutines other
+// than the test goroutine.
+func (l *LockStep) EmitE(m string) error {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	if err := l.emit(context.Background(), m, nil, l.opTimeout(m)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// emit emits m carrying the value v, through the emit interceptors. See
+// EmitValue.
+func (l *LockStep) emit(ctx context.Context, m string, v any, timeout time.Duration) *LockStepError {
+	l.t.Helper()
+
+	var err *LockStepError
+	l.interceptEmit(m, func(m string) {
+		traceRegion(ctx, "lockstep.emit", m, func() {
+			err = l.emitDirect(ctx, m, v, timeout)
+		})
+	})
+	return err
+}
+
+// emitDirect is like emit, but it bypasses the emit interceptors.
+func (l *LockStep) emitDirect(ctx context.Context, m string, v any, timeout time.Duration) *LockStepError {
+	l.t.Helper()
 
 	l.logf("Emiting %v", m)
 
+	if l.tracer != nil {
+		span := l.tracer.Start(ctx, "lockstep.emit", m)
+		defer span.End()
+		ctx = contextWithSpan(ctx, span)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	l.delay(ctx)
+
+	if err := l.awaitTurn(ctx, m); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.checkDoubleEmitWithLock(m); err != nil {
+		return err
+	}
+	if !l.emitWithLock(ctx, m, v, false) {
+		return l.ctxError(ctx, OpEmit, m, "emitting %v", m)
+	}
+	return nil
+}
+
+// EmitAfter emits the message m after d, from a separate goroutine. The
+// returned function cancels the emit: if it has not started yet, it never
+// will; if it is blocked waiting for a corresponding Wait, it is abandoned
+// without failing.
+func (l *LockStep) EmitAfter(d time.Duration, m string) context.CancelFunc {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	l.logf("Scheduling emit of %v in %v", m, d)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	timer := time.AfterFunc(d, func() {
+		if err := l.emit(ctx, m, nil, l.opTimeout(m)); err != nil && ctx.Err() == nil {
+			l.fail(err)
+		}
+	})
+
+	return func() {
+		if timer.Stop() {
+			l.logf("Cancelled scheduled emit of %v", m)
+		}
+		cancel()
+	}
+}
+
+// EmitN emits the message m n times in sequence, as if Emit(m) was called n
+// times. The timeout applies to all n operations together.
+func (l *LockStep) EmitN(m string, n int) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	l.interceptEmit(m, func(m string) {
+		l.emitN(m, n)
+	})
+}
+
+// emitN is like EmitN, but it bypasses the emit interceptors.
+func (l *LockStep) emitN(m string, n int) {
+	l.t.Helper()
+
+	l.logf("Emiting %v (x%v)", m, n)
+
+	ctx := context.Background()
+	if l.tracer != nil {
+		span := l.tracer.Start(ctx, "lockstep.emit", m)
+		defer span.End()
+		ctx = contextWithSpan(ctx, span)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.opTimeout(m))
+	defer cancel()
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	deadline := time.Now().Add(l.timeout)
+	if err := l.checkDoubleEmitWithLock(m); err != nil {
+		l.fail(err)
+		return
+	}
+	for i := 0; i < n; i++ {
+		if !l.emitWithLock(ctx, m, nil, false) {
+			l.fail(l.ctxError(ctx, OpEmit, m, "emitting %v (%v of %v)", m, i+1, n))
+			return
+		}
+	}
+}
+
+// Broadcast is like Emit, but it satisfies all the Wait operations in progress
+// for m instead of just one. It blocks until there is at least one. For
+// several goroutines to wait for a broadcast message at the same time, use the
+// Queue double wait policy (see [WithDoubleWaitPolicy]):
+//
+//	ls := lockstep.New(t, lockstep.WithDoubleWaitPolicy(lockstep.Queue))
+//	for i := 0; i < 3; i++ {
+//		go func() {
+//			ls.Wait("reload")
+//			// ...
+//		}()
+//	}
+//	ls.Broadcast("reload")
+func (l *LockStep) Broadcast(m string) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	l.interceptEmit(m, func(m string) {
+		l.logf("Broadcasting %v", m)
+
+		if err := l.broadcast(m); err != nil {
+			l.fail(err)
+		}
+	})
+}
+
+// broadcast is like Broadcast, but it returns failures.
+func (l *LockStep) broadcast(m string) *LockStepError {
+	l.t.Helper()
+
+	ctx := context.Background()
+	if l.tracer != nil {
+		span := l.tracer.Start(ctx, "lockstep.emit", m)
+		defer span.End()
+		ctx = contextWithSpan(ctx, span)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, l.opTimeout(m))
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.checkDoubleEmitWithLock(m); err != nil {
+		return err
+	}
+	if !l.emitWithLock(ctx, m, nil, true) {
+		return l.ctxError(ctx, OpEmit, m, "broadcasting %v", m)
+	}
+	return nil
+}
+
+// Once latches the message m: all the Wait operations for m, those in progress
+// and any future ones, are satisfied immediately. Once never blocks. Emit
+// operations for a latched message also return immediately. Latches are
+// cleared by [LockStep.Reset].
+func (l *LockStep) Once(m string) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	l.interceptEmit(m, l.once)
+}
+
+// once is like Once, but it bypasses the emit interceptors.
+func (l *LockStep) once(m string) {
+	l.logf("Latching %v", m)
+
+	var span Span
+	if l.tracer != nil {
+		span = l.tracer.Start(context.Background(), "lockstep.emit", m)
+		defer span.End()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.startEmitWithLock(m)
+	l.latched[m] = true
+
+	now := time.Now()
+	for l.matchWithLock(m, now, nil, span) {
+	}
+
+	// Release the Emit operations blocked on m.
+	l.broadcastWithLock()
+}
+
+// startEmitWithLock records the start of an Emit operation for m and forwards
+// it to the instances bridged to l. l.mu must be held.
+func (l *LockStep) startEmitWithLock(m string) {
+	l.recordWithLock(OpEmit, m, 0)
+	l.emits[m]++
+	l.startTurnWithLock(m)
+
+	// Let observers of l.emits (e.g. MustNotEmit) know, even if the Emit
+	// operation completes without matching, e.g. when it is buffered.
+	l.broadcastWithLock()
+
+	for _, b := range l.bridges[m] {
+		go b.dst.forward(b.m)
+	}
+}
+
+// emitWithLock blocks until m, carrying the value v, is matched by a Wait
+// operation. If all is set, m is matched with all the Wait operations for m. It
+// returns false if ctx is done first. l.mu must be held.
+func (l *LockStep) emitWithLock(ctx context.Context, m string, v any, all bool) bool {
+	l.t.Helper()
+
+	if l.cancelled() {
+		return false
+	}
+
+	span := spanFromContext(ctx)
+	start := time.Now()
+	l.startEmitWithLock(m)
+
+	l.emitSeq++
+	ticket := l.emitSeq
+	l.emitting[m]++
+	l.emitQueue[m] = append(l.emitQueue[m], ticket)
+	defer l.emitDoneWithLock(m, ticket)
+
+	defer l.startSoftTimeoutWithLock(func() string {
+		return "emitting " + m
+	})()
+
+	// Let observers of l.emitting (e.g. WaitOrdered) know.
+	l.broadcastWithLock()
+
 	for {
-		if l.waiting[m] {
+		if l.latched[m] {
+			l.logf("Emitted %v (latched)", m)
+			return true
+		}
+
+		// Only the oldest blocked Emit operation can be matched.
+		if l.emitQueue[m][0] != ticket {
+			if !l.waitWithLock(ctx) {
+				l.recordTimeoutWithLock(OpEmit, m, time.Since(start))
+				return false
+			}
+			continue
+		}
+
+		if l.consumed[m] > 0 {
 			l.logf("Emitted %v", m)
-			delete(l.waiting, m)
-			l.cv.Broadcast()
-			return
+			l.consumed[m]--
+			return true
+		}
+
+		if l.matchWithLock(m, start, v, span) {
+			for all && l.matchWithLock(m, start, v, span) {
+			}
+			l.logf("Emitted %v", m)
+			return true
 		}
 
-		if !l.waitWithLock(deadline) {
-			l.t.Fatalf("Timeout emitting %v", m)
+		if !l.waitWithLock(ctx) {
+			l.recordTimeoutWithLock(OpEmit, m, time.Since(start))
+			return false
+		}
+	}
+}
+
+// TryEmit emits the message m only if a corresponding Wait operation is
+// already in progress. It never blocks. It returns true if m was emitted.
+func (l *LockStep) TryEmit(m string) bool {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	emitted := true
+	l.interceptEmit(m, func(m string) {
+		emitted = l.tryEmit(m)
+	})
+	return emitted
+}
+
+// tryEmit is like TryEmit, but it bypasses the emit interceptors.
+func (l *LockStep) tryEmit(m string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.waiting[m] == nil && l.patternWaiterWithLock(m) == nil {
+		l.logf("Not emitted %v", m)
+		return false
+	}
+
+	l.startEmitWithLock(m)
+	l.matchWithLock(m, time.Now(), nil, nil)
+	l.logf("Emitted %v", m)
+	return true
+}
+
+// MustNotEmit blocks for d and fails immediately if an Emit operation for m
+// starts in the meantime. It does not affect the Emit operation.
+func (l *LockStep) MustNotEmit(m string, d time.Duration) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	l.logf("Expecting no emit of %v for %v", m, d)
+
+	ctx, cancel := context.WithTimeout(withTimerBound(context.Background()), d)
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	emits := l.emits[m]
+	for l.waitWithLock(ctx) {
+		if l.emits[m] != emits {
+			l.fail(newError(OpEmit, m, "unexpected emit", "Unexpected emit: %v", m))
+			return
 		}
 	}
 }
 
+// emitDoneWithLock unregisters an Emit operation for m that is returning,
+// successfully or not. ticket identifies the operation in l.emitQueue. l.mu
+// must be held.
+func (l *LockStep) emitDoneWithLock(m string, ticket uint64) {
+	l.emitQueue[m] = slices.DeleteFunc(l.emitQueue[m], func(t uint64) bool {
+		return t == ticket
+	})
+	if len(l.emitQueue[m]) == 0 {
+		delete(l.emitQueue, m)
+	}
+
+	l.emitting[m]--
+	if l.consumed[m] > l.emitting[m] {
+		// An Emit that failed cannot keep a TryWait grant.
+		l.consumed[m] = l.emitting[m]
+	}
+	if l.emitting[m] == 0 {
+		delete(l.emitting, m)
+		delete(l.consumed, m)
+	}
+
+	// Let observers of l.emitting (e.g. WaitForIdle) know.
+	l.broadcastWithLock()
+}
+
 // Wait waits for all the provided messages. It will block until Emit operations
 // corresponding to all messages have been processed.
 //
@@ -133,70 +866,714 @@ func (l *LockStep) Emit(m string) {
 // This Wait will only be fulfilled if x and y are emitted in order.
 func (l *LockStep) Wait(ms ...string) {
 	l.t.Helper()
+	l.WaitCtx(context.Background(), ms...)
+}
 
-	l.logf("Waiting for %v", messageList(slices.Values(ms)))
+// WaitCtx is like Wait, but it will also fail if ctx is done before all the
+// messages are emitted. If ctx is already done, WaitCtx fails immediately
+// without registering any of the messages.
+func (l *LockStep) WaitCtx(ctx context.Context, ms ...string) {
+	l.t.Helper()
 
-	waiting := make(map[string]bool, len(ms))
+	ms = l.qualifyAll(ms)
+
+	if err := l.wait(ctx, ms, l.opTimeout(ms...)); err != nil {
+		l.fail(err)
+	}
+}
+
+// WaitWithin is like Wait, but it uses d as the timeout instead of the one
+// configured for the LockStep instance. Use it to assert that the messages are
+// emitted within a specific window.
+func (l *LockStep) WaitWithin(d time.Duration, ms ...string) {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	if err := l.wait(withTimerBound(context.Background()), ms, l.capTimeout(d)); err != nil {
+		l.fail(err)
+	}
+}
+
+// WaitE is like Wait, but it returns a *LockStepError on failure instead of
+// reporting it to the test. This makes it safe to use from goroutines other
+// than the test goroutine.
+func (l *LockStep) WaitE(ms ...string) error {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	if err := l.wait(context.Background(), ms, l.opTimeout(ms...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// wait waits for all the messages in ms, through the wait interceptors.
+func (l *LockStep) wait(ctx context.Context, ms []string, timeout time.Duration) *LockStepError {
+	l.t.Helper()
+
+	var err *LockStepError
+	l.interceptWait(ms, func(ms []string) {
+		traceRegion(ctx, "lockstep.wait", messageList(ms), func() {
+			err = l.waitDirect(ctx, ms, timeout)
+		})
+	})
+	return err
+}
+
+// waitDirect is like wait, but it bypasses the wait interceptors.
+func (l *LockStep) waitDirect(ctx context.Context, ms []string, timeout time.Duration) *LockStepError {
+	l.t.Helper()
+
+	if ctx.Err() != nil {
+		msgs := messageList(ms)
+		return l.ctxError(ctx, OpWait, msgs, "waiting for %v", msgs)
+	}
+
+	l.logf("Waiting for %v", messageList(ms))
+
+	w := newWaiter(false)
+	if l.tracer != nil {
+		w.span = l.tracer.Start(ctx, "lockstep.wait", messageList(ms))
+		defer w.span.End()
+	}
+
+	l.delay(ctx)
 
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	for _, m := range ms {
-		if l.waiting[m] {
-			l.t.Fatalf("Double wait for %v", m)
+	return l.awaitWithLock(ctx, w, ms, 1, timeout)
+}
+
+// WaitN waits for n Emit operations for the message m. The timeout applies to
+// all n operations together. If n is zero, WaitN returns immediately.
+//
+//	for i := 0; i < 10; i++ {
+//		go func() {
+//			// ...
+//			ls.Emit("task-done")
+//		}()
+//	}
+//	ls.WaitN("task-done", 10)
+func (l *LockStep) WaitN(m string, n int) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	if n <= 0 {
+		return
+	}
+
+	l.interceptWait([]string{m}, func(ms []string) {
+		if err := l.waitN(context.Background(), ms, n); err != nil {
+			l.fail(err)
 		}
-		l.waiting[m] = true
-		waiting[m] = true
+	})
+}
+
+// waitN is like WaitN, but it returns failures, and it bypasses the wait
+// interceptors.
+func (l *LockStep) waitN(ctx context.Context, ms []string, n int) *LockStepError {
+	l.t.Helper()
+
+	l.logf("Waiting for %v (x%v)", messageList(ms), n)
+
+	w := newWaiter(false)
+	if l.tracer != nil {
+		w.span = l.tracer.Start(ctx, "lockstep.wait", messageList(ms))
+		defer w.span.End()
 	}
 
-	l.cv.Broadcast()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.awaitWithLock(ctx, w, ms, n, l.opTimeout(ms...))
+}
+
+// WaitAny waits for any one of the provided messages. It will block until an
+// Emit operation corresponding to one of the messages is processed, and it
+// returns the message that was emitted. The remaining messages are
+// unregistered and may be waited on again. If the operation fails and the
+// failure mode allows it to return, WaitAny returns "".
+func (l *LockStep) WaitAny(ms ...string) string {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	m := ""
+	l.interceptWait(ms, func(ms []string) {
+		l.logf("Waiting for any of %v", messageList(ms))
+
+		var err *LockStepError
+		if m, err = l.waitAny(ms); err != nil {
+			l.fail(err)
+		}
+	})
+	return l.unqualify(m)
+}
+
+// waitAny is like WaitAny, but it returns failures.
+func (l *LockStep) waitAny(ms []string) (string, *LockStepError) {
+	l.t.Helper()
+
+	w := newWaiter(true)
+	if l.tracer != nil {
+		w.span = l.tracer.Start(context.Background(), "lockstep.wait", messageList(ms))
+		defer w.span.End()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.awaitWithLock(context.Background(), w, ms, 1, l.opTimeout(ms...)); err != nil {
+		return "", err
+	}
+	return w.matched[0], nil
+}
+
+// WaitAnyOf waits until all the messages of any one of the provided groups
+// have been emitted. It returns the group that completed and its index. The
+// Wait operations for the other groups are abandoned, and the messages of
+// those groups emitted in the meantime are consumed.
+//
+//	_, i := ls.WaitAnyOf(
+//		[]string{"success-a", "success-b"},
+//		[]string{"failure-x", "failure-y"})
+//
+// A message must not appear in more than one group. If the operation fails and
+// the failure mode allows it to return, WaitAnyOf returns nil and -1.
+func (l *LockStep) WaitAnyOf(groups ...[]string) (matched []string, index int) {
+	l.t.Helper()
+
+	descs := make([]string, len(groups))
+	qgroups := make([][]string, len(groups))
+	var all []string
+	for i, g := range groups {
+		qgroups[i] = l.qualifyAll(g)
+		descs[i] = "[" + messageList(qgroups[i]) + "]"
+		all = append(all, qgroups[i]...)
+	}
+	desc := strings.Join(descs, " or ")
+
+	l.logf("Waiting for any of %v", desc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.opTimeout(all...))
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ws := make([]*waiter, 0, len(groups))
+	defer func() {
+		for _, w := range ws {
+			l.unregisterWithLock(w)
+		}
+	}()
+	for _, g := range qgroups {
+		w := newWaiter(false)
+		if err := l.registerWithLock(w, g, 1); err != nil {
+			l.fail(err)
+			return nil, -1
+		}
+		ws = append(ws, w)
+	}
+
+	defer l.startSoftTimeoutWithLock(func() string {
+		return "waiting for any of " + desc
+	})()
 
-	deadline := time.Now().Add(l.timeout)
 	for {
-		for m := range waiting {
-			if !l.waiting[m] {
-				l.logf("Wait satisfied for %v", m)
-				delete(waiting, m)
-				l.cv.Broadcast()
+		for i, w := range ws {
+			if w.done() {
+				return slices.Clone(groups[i]), i
+			}
+		}
+		if !l.waitWithLock(ctx) {
+			for _, w := range ws {
+				l.timeoutWithLock(w)
 			}
+			l.fail(l.ctxError(ctx, OpWait, desc, "waiting for any of %v", desc))
+			return nil, -1
+		}
+	}
+}
+
+// WaitOrdered waits for all the provided messages, which must be emitted in
+// the order given. It fails if a message is emitted before the ones preceding
+// it. The timeout applies to all the messages together.
+//
+//	ls.WaitOrdered("x", "y")
+//
+// This is like calling Wait("x") followed by Wait("y"), except that an Emit of
+// y while waiting for x fails immediately instead of timing out.
+func (l *LockStep) WaitOrdered(ms ...string) {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	l.logf("Waiting for %v in order", strings.Join(ms, ", "))
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.opTimeout(ms...))
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i := range ms {
+		if err := l.waitOrderedWithLock(ctx, ms[i:]); err != nil {
+			l.fail(err)
+			return
 		}
+	}
+}
 
-		if len(waiting) == 0 {
-			break
+// waitOrderedWithLock waits for ms[0], failing if any of the following
+// messages is emitted first. l.mu must be held.
+func (l *LockStep) waitOrderedWithLock(ctx context.Context, ms []string) *LockStepError {
+	l.t.Helper()
+
+	w := newWaiter(false)
+	if err := l.registerWithLock(w, ms[:1], 1); err != nil {
+		return err
+	}
+	defer l.unregisterWithLock(w)
+
+	remaining := strings.Join(ms, ", ")
+	defer l.startSoftTimeoutWithLock(func() string {
+		return "waiting for " + remaining + " in order"
+	})()
+	for !w.done() {
+		for _, r := range ms[1:] {
+			if l.emitting[r] > l.consumed[r] {
+				return newError(
+					OpWait, remaining, "out of order",
+					"Out of order emit: expected %v, actual %v, remaining %v", ms[0], r, remaining)
+			}
 		}
 
-		if !l.waitWithLock(deadline) {
-			l.t.Fatalf("Timeout waiting for %v", messageList(maps.Keys(waiting)))
+		if !l.waitWithLock(ctx) {
+			l.timeoutWithLock(w)
+			return l.ctxError(ctx, OpWait, remaining, "waiting for %v in order", remaining)
 		}
 	}
+
+	return nil
 }
 
-func (l *LockStep) waitWithLock(deadline time.Time) bool {
+// registerWithLock registers w for n Emit operations of each of the messages
+// in ms. l.mu must be held.
+func (l *LockStep) registerWithLock(w *waiter, ms []string, n int) *LockStepError {
 	l.t.Helper()
 
-	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	if err := l.cancelledError(OpWait, messageList(ms)); err != nil {
+		return err
+	}
+
+	for _, m := range ms {
+		if (l.waiting[m] != nil && l.doubleWait == Fail) || w.pending[m] != 0 {
+			w.pending = make(map[string]int)
+			return newError(OpWait, m, "double wait", "Double wait for %v", m)
+		}
+		w.pending[m] = n
+	}
+
+	w.start = time.Now()
+	for _, m := range ms {
+		if l.waiting[m] == nil {
+			l.waiting[m] = w
+		} else {
+			l.waitQueue[m] = append(l.waitQueue[m], w)
+		}
+		l.recordWithLock(OpWait, m, 0)
+	}
+
+	// Latched and buffered messages are satisfied right away.
+	for _, m := range ms {
+		for l.latched[m] && l.matchWithLock(m, w.start, nil, nil) {
+		}
+		l.consumeBufferedWithLock(m, w.start)
+	}
+
+	l.broadcastWithLock()
+
+	return nil
+}
+
+// awaitWithLock registers w for n Emit operations of each of the messages in
+// ms and blocks until w is satisfied, ctx is done, or timeout expires. w is
+// always unregistered before returning. l.mu must be held.
+func (l *LockStep) awaitWithLock(
+	ctx context.Context, w *waiter, ms []string, n int, timeout time.Duration,
+) *LockStepError {
+	l.t.Helper()
+
+	if err := l.registerWithLock(w, ms, n); err != nil {
+		return err
+	}
+	defer l.unregisterWithLock(w)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	defer l.startSoftTimeoutWithLock(func() string {
+		return "waiting for " + w.String()
+	})()
+
+	for !w.done() {
+		if !l.waitWithLock(ctx) {
+			l.timeoutWithLock(w)
+			return l.ctxError(ctx, OpWait, w.String(), "waiting for %v", w)
+		}
+	}
+
+	return nil
+}
+
+// matchWithLock matches an emitted message m against the registered waiters.
+// start is the time the Emit operation started, v is the value it carries, and
+// span is its trace span, if any. It returns false if nobody is waiting for m.
+// l.mu must be held.
+func (l *LockStep) matchWithLock(m string, start time.Time, v any, span Span) bool {
+	w := l.waiting[m]
+	if w == nil {
+		w = l.patternWaiterWithLock(m)
+	}
+	if w == nil {
+		return false
+	}
+
+	l.logf("Wait satisfied for %v", m)
+
+	l.hooks.matched(m, w.start, start)
+	if w.span != nil && span != nil {
+		w.span.AddLink(span)
+	}
+	if w.start.Before(start) {
+		start = w.start
+	}
+	l.recordWithLock(OpMatch, m, time.Since(start))
+
+	w.matched = append(w.matched, m)
+	if v != nil {
+		if w.values == nil {
+			w.values = make(map[string]any)
+		}
+		w.values[m] = v
+	}
+	l.hooks.emitted(m)
+	if w.match != nil {
+		l.removePatternWithLock(w)
+	} else if w.any {
+		l.unregisterWithLock(w)
+		w.pending = make(map[string]int)
+	} else if w.pending[m]--; w.pending[m] == 0 {
+		l.removeWaiterWithLock(m, w)
+		delete(w.pending, m)
+	}
+
+	l.broadcastWithLock()
+	return true
+}
+
+// startSoftTimeoutWithLock arranges for a warning to be logged if the
+// operation in progress is not done within the soft timeout. what describes
+// the operation; it is called with l.mu held. The returned function must be
+// called, with l.mu held, when the operation is done.
+func (l *LockStep) startSoftTimeoutWithLock(what func() string) (stop func()) {
+	if l.softTimeout <= 0 {
+		return func() {}
+	}
+
+	done := false
+	timer := time.AfterFunc(l.softTimeout, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !done {
+			l.t.Logf("soft timeout: still %v after %v", what(), l.softTimeout)
+		}
+	})
+
+	return func() {
+		done = true
+		timer.Stop()
+	}
+}
+
+// timeoutWithLock records the timeout of all the messages still pending for w.
+// l.mu must be held.
+func (l *LockStep) timeoutWithLock(w *waiter) {
+	elapsed := time.Since(w.start)
+	for _, m := range slices.Sorted(maps.Keys(w.pending)) {
+		l.recordTimeoutWithLock(OpWait, m, elapsed)
+	}
+}
+
+// unregisterWithLock removes all the registrations of w that are still
+// pending. l.mu must be held.
+func (l *LockStep) unregisterWithLock(w *waiter) {
+	for m := range w.pending {
+		l.removeWaiterWithLock(m, w)
+	}
+	l.broadcastWithLock()
+}
+
+// removeWaiterWithLock removes the registration of w for m. If w was the
+// current waiter for m, the next queued waiter, if any, takes its place. l.mu
+// must be held.
+func (l *LockStep) removeWaiterWithLock(m string, w *waiter) {
+	q := l.waitQueue[m]
+	if l.waiting[m] == w {
+		if len(q) == 0 {
+			delete(l.waiting, m)
+			return
+		}
+		l.waiting[m] = q[0]
+		q = q[1:]
+	} else {
+		q = slices.DeleteFunc(q, func(qw *waiter) bool {
+			return qw == w
+		})
+	}
+	if len(q) == 0 {
+		delete(l.waitQueue, m)
+	} else {
+		l.waitQueue[m] = q
+	}
+}
+
+// TryWait checks whether an Emit operation for any of the provided messages is
+// already in progress. If so, it consumes that message, unblocking the Emit,
+// and returns it. It never blocks. ok is false if none of the messages is
+// available.
+func (l *LockStep) TryWait(ms ...string) (matched string, ok bool) {
+	l.t.Helper()
+
+	ms = l.qualifyAll(ms)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, m := range ms {
+		if l.latched[m] {
+			l.logf("Wait satisfied for %v (latched)", m)
+			l.recordWithLock(OpWait, m, 0)
+			l.recordWithLock(OpMatch, m, 0)
+			l.hooks.emitted(m)
+			l.hooks.matched(m, time.Now(), time.Time{})
+			return l.unqualify(m), true
+		}
+
+		if l.buffered[m] > 0 {
+			l.logf("Wait satisfied for %v (buffered)", m)
+			l.recordWithLock(OpWait, m, 0)
+			l.recordWithLock(OpMatch, m, 0)
+			l.hooks.emitted(m)
+			l.hooks.matched(m, time.Now(), time.Time{})
+			if l.buffered[m]--; l.buffered[m] == 0 {
+				delete(l.buffered, m)
+			}
+			l.broadcastWithLock()
+			return l.unqualify(m), true
+		}
+
+		if l.emitting[m] > l.consumed[m] {
+			l.logf("Wait satisfied for %v", m)
+			l.recordWithLock(OpWait, m, 0)
+			l.recordWithLock(OpMatch, m, 0)
+			l.hooks.emitted(m)
+			l.hooks.matched(m, time.Now(), time.Time{})
+			l.consumed[m]++
+			l.broadcastWithLock()
+			return l.unqualify(m), true
+		}
+	}
+
+	return "", false
+}
+
+// checkPending reports any messages that are still pending.
+func (l *LockStep) checkPending() {
+	if l.cancelled() {
+		// The operations are failing on their own.
+		return
+	}
+	if pending := l.Pending(); len(pending) != 0 {
+		l.t.Errorf("Abandoned wait for %v", strings.Join(pending, ", "))
+	}
+}
+
+// Pending returns the sorted list of messages that Wait operations in progress
+// are waiting for.
+func (l *LockStep) Pending() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.pendingWithLock()
+}
+
+// pendingWithLock returns the sorted list of messages and patterns that Wait
+// operations in progress are waiting for. l.mu must be held.
+func (l *LockStep) pendingWithLock() []string {
+	pending := slices.Collect(maps.Keys(l.waiting))
+	for _, w := range l.patterns {
+		pending = append(pending, w.desc)
+	}
+	slices.Sort(pending)
+	return pending
+}
+
+// PendingCount returns the number of messages that Wait operations in progress
+// are waiting for. It is equivalent to len(l.Pending()), but it does not
+// allocate.
+func (l *LockStep) PendingCount() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return len(l.waiting) + len(l.patterns)
+}
+
+// Drain blocks until no Wait operations are in progress, i.e. until all the
+// pending messages have been emitted. Unlike Wait, it does not register any
+// messages. It returns an error if the timeout expires first.
+func (l *LockStep) Drain(timeout time.Duration) error {
+	l.t.Helper()
+
+	ctx, cancel := context.WithTimeout(withTimerBound(context.Background()), timeout)
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for len(l.waiting) != 0 || len(l.patterns) != 0 {
+		if !l.waitWithLock(ctx) {
+			return fmt.Errorf("timeout draining: still waiting for %v", strings.Join(l.pendingWithLock(), ", "))
+		}
+	}
+
+	return nil
+}
+
+// WaitForIdle blocks until no Emit or Wait operations are in progress. Unlike
+// [LockStep.Drain], it also waits for blocked Emit operations. It returns an
+// error listing the operations still in progress if the timeout expires
+// first.
+func (l *LockStep) WaitForIdle(timeout time.Duration) error {
+	l.t.Helper()
+
+	ctx, cancel := context.WithTimeout(withTimerBound(context.Background()), timeout)
 	defer cancel()
 
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for len(l.waiting) != 0 || len(l.patterns) != 0 || len(l.emitting) != 0 {
+		if !l.waitWithLock(ctx) {
+			return fmt.Errorf(
+				"timeout waiting for idle: still waiting for [%v], emitting [%v]",
+				strings.Join(l.pendingWithLock(), ", "), messageList(slices.Collect(maps.Keys(l.emitting))))
+		}
+	}
+
+	return nil
+}
+
+// broadcastWithLock wakes up all the blocked operations. l.mu must be held.
+func (l *LockStep) broadcastWithLock() {
+	l.unblockAllWithLock()
+	l.cv.Broadcast()
+}
+
+// waitWithLock waits until a change is broadcast (see broadcastWithLock). It
+// returns false if ctx is done before or while waiting. l.mu must be held.
+func (l *LockStep) waitWithLock(ctx context.Context) bool {
+	l.t.Helper()
+
+	unblock := l.blockWithLock(isTimerBound(ctx))
+	defer unblock()
+
+	if ctx.Err() != nil || l.cancelled() {
+		return false
+	}
+
+	// Wake up when ctx is done. Unlike a goroutine blocked on ctx.Done(), this
+	// only arms a timer for the deadline, so in-flight operations don't cost a
+	// goroutine each.
 	var timedOut atomic.Bool
-	go func() {
-		<-ctx.Done()
-		l.cv.Broadcast()
+	stop := context.AfterFunc(ctx, func() {
 		timedOut.Store(true)
-	}()
+		l.mu.Lock()
+		l.cv.Broadcast()
+		l.mu.Unlock()
+	})
+	defer stop()
 
 	l.cv.Wait()
 
-	return !timedOut.Load()
+	return !timedOut.Load() && ctx.Err() == nil && !l.cancelled()
 }
 
-func (l *LockStep) logf(msg string, args ...any) {
-	if l.verbose {
-		l.t.Logf(msg, args...)
+// waiter is a Wait operation in progress.
+type waiter struct {
+	// pending holds the number of Emit operations the waiter is still waiting
+	// for, by message.
+	pending map[string]int
+	// any is set if the waiter is satisfied by the first emitted message.
+	any bool
+	// matched holds the messages emitted for the waiter, in order.
+	matched []string
+	// values holds the values carried by the matched Emit operations, if
+	// any, by message. See EmitValue.
+	values map[string]any
+	// start is the time the waiter was registered.
+	start time.Time
+	// match is set for pattern waiters, which are satisfied by the first
+	// emitted message for which it returns true. desc describes the pattern.
+	match func(m string) bool
+	desc  string
+	// span is the trace span of the Wait operation, if any. See WithTracer.
+	span Span
+}
+
+func newWaiter(any bool) *waiter {
+	return &waiter{
+		pending: make(map[string]int),
+		any:     any,
 	}
 }
 
-func messageList(ms iter.Seq[string]) string {
-	k := slices.Collect(ms)
+func (w *waiter) done() bool {
+	return len(w.pending) == 0
+}
+
+func (w *waiter) String() string {
+	if w.match != nil {
+		return w.desc
+	}
+	k := make([]string, 0, len(w.pending))
+	for m, n := range w.pending {
+		if n > 1 {
+			m = fmt.Sprintf("%v (x%v)", m, n)
+		}
+		k = append(k, m)
+	}
+	ms := messageList(k)
+	if w.any {
+		return "any of " + ms
+	}
+	return ms
+}
+
+// messageList returns the messages in ms sorted and separated by commas. ms is
+// not modified.
+func messageList(ms []string) string {
+	// Most operations involve a single message: return it as is, without
+	// allocating.
+	if len(ms) == 1 {
+		return ms[0]
+	}
+	k := slices.Clone(ms)
 	slices.Sort(k)
 	return strings.Join(k, ", ")
 }
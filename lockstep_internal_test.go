@@ -0,0 +1,39 @@
+package lockstep
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMessageList(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		ms   []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"x"}, "x"},
+		{[]string{"y", "x"}, "x, y"},
+		{[]string{"z", "x", "y"}, "x, y, z"},
+	} {
+		orig := slices.Clone(tc.ms)
+		if got := messageList(tc.ms); got != tc.want {
+			t.Fatalf("messageList(%q): expected %q, actual %q", tc.ms, tc.want, got)
+		}
+		if !slices.Equal(orig, tc.ms) {
+			t.Fatalf("messageList(%q) modified its argument", orig)
+		}
+	}
+}
+
+func BenchmarkMessageList(b *testing.B) {
+	for _, ms := range [][]string{{"x"}, {"x", "y", "z"}} {
+		b.Run(messageList(ms), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				messageList(ms)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package lockstep_test
 
 import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -128,6 +133,852 @@ func TestLockStep_MultiWaitTimeout(t *testing.T) {
 	})
 }
 
+func TestLockStep_EmitCtx(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Wait("x")
+	}()
+
+	ls.EmitCtx(context.Background(), "x")
+}
+
+func TestLockStep_EmitCtxCancel(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	expectFail(t, func() {
+		ls.EmitCtx(ctx, "x")
+	})
+}
+
+func TestLockStep_EmitCtxDeadline(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.EmitCtx(ctx, "x")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected context deadline to win, took %v", dur)
+	}
+}
+
+func TestLockStep_WaitCtx(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Emit("x")
+		ls.Emit("y")
+	}()
+
+	ls.WaitCtx(context.Background(), "x", "y")
+}
+
+func TestLockStep_WaitCtxCancel(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	go func() {
+		ls.Emit("x")
+	}()
+
+	expectFail(t, func() {
+		ls.WaitCtx(ctx, "x", "y")
+	})
+}
+
+func TestLockStep_WaitCtxAlreadyCancelled(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	expectFail(t, func() {
+		ls.WaitCtx(ctx, "x")
+	})
+
+	// The failed WaitCtx must not have registered x.
+	go func() {
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+}
+
+func TestLockStep_TryEmit(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	expectEqual(t, false, ls.TryEmit("x"))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Wait("x")
+	}()
+
+	for !ls.TryEmit("x") {
+		time.Sleep(10 * time.Millisecond)
+	}
+	<-done
+
+	expectEqual(t, false, ls.TryEmit("x"))
+}
+
+func TestLockStep_TryWait(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	_, ok := ls.TryWait("x", "y")
+	expectEqual(t, false, ok)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Emit("y")
+	}()
+
+	var m string
+	for !ok {
+		time.Sleep(10 * time.Millisecond)
+		m, ok = ls.TryWait("x", "y")
+	}
+	expectEqual(t, "y", m)
+	<-done
+
+	_, ok = ls.TryWait("x", "y")
+	expectEqual(t, false, ok)
+}
+
+func TestLockStep_TryWaitThenWait(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Emit("x")
+		ls.Emit("x")
+	}()
+
+	for {
+		if _, ok := ls.TryWait("x"); ok {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	ls.Wait("x")
+}
+
+func TestLockStep_WaitAny(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Emit("y")
+	}()
+
+	expectEqual(t, "y", ls.WaitAny("x", "y", "z"))
+
+	// The remaining messages were unregistered and can be waited on again.
+	go func() {
+		ls.Emit("z")
+		ls.Emit("x")
+	}()
+
+	ls.Wait("x", "z")
+}
+
+func TestLockStep_WaitAnyConcurrentEmits(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Emit("x")
+	}()
+	go func() {
+		ls.Emit("y")
+	}()
+
+	first := ls.WaitAny("x", "y")
+	second := "x"
+	if first == "x" {
+		second = "y"
+	}
+	ls.Wait(second)
+}
+
+func TestLockStep_WaitAnyTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+	ls.SetTimeout(100 * time.Millisecond)
+
+	expectFail(t, func() {
+		ls.WaitAny("x", "y")
+	})
+}
+
+func TestLockStep_WaitN(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	for i := 0; i < 10; i++ {
+		go func() {
+			ls.Emit("task-done")
+		}()
+	}
+
+	ls.WaitN("task-done", 10)
+}
+
+func TestLockStep_WaitNTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+	ls.SetTimeout(100 * time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		go func() {
+			ls.Emit("task-done")
+		}()
+	}
+
+	expectFail(t, func() {
+		ls.WaitN("task-done", 3)
+	})
+}
+
+func TestLockStep_EmitN(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.EmitN("item", 3)
+		ls.Emit("done")
+	}()
+
+	ls.Wait("item")
+	ls.WaitN("item", 2)
+	ls.Wait("done")
+}
+
+func TestLockStep_EmitNTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+	ls.SetTimeout(100 * time.Millisecond)
+
+	go func() {
+		ls.Wait("item")
+	}()
+
+	expectFail(t, func() {
+		ls.EmitN("item", 2)
+	})
+}
+
+func TestLockStep_WaitOrdered(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Emit("x")
+		ls.Emit("y")
+		ls.Emit("z")
+	}()
+
+	ls.WaitOrdered("x", "y", "z")
+}
+
+func TestLockStep_WaitOrderedViolation(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	go func() {
+		ls.Emit("y")
+	}()
+
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.WaitOrdered("x", "y")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected immediate failure, took %v", dur)
+	}
+
+	ls.Wait("y")
+}
+
+func TestLockStep_Reset(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	ls.SetTimeout(time.Second)
+
+	for _, m := range []string{"a", "b"} {
+		t.Run(m, func(t *testing.T) {
+			ls.Reset()
+			go func() {
+				ls.Emit(m)
+			}()
+			ls.Wait(m)
+		})
+	}
+}
+
+func TestLockStep_ResetInProgress(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	go func() {
+		ls.Emit("x")
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	expectFail(t, func() {
+		ls.Reset()
+	})
+
+	ls.Wait("x")
+	ls.Reset()
+}
+
+func TestLockStep_ResetInProgress_Rendezvous(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Rendezvous("r")
+	}()
+	for len(ls.History()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	expectFail(t, func() {
+		ls.Reset()
+	})
+
+	ls.Rendezvous("r")
+	<-done
+	ls.Reset()
+}
+
+func TestLockStep_ResetInProgress_Sequence(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	ls.ExpectSequence("a")
+	ls.Emit("a")
+	expectFail(t, func() {
+		ls.Reset()
+	})
+
+	ls.VerifySequence()
+	ls.Reset()
+}
+
+func TestLockStep_Pending(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	expectEqual(t, 0, len(ls.Pending()))
+	expectEqual(t, 0, ls.PendingCount())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Wait("y", "x")
+	}()
+
+	for ls.PendingCount() != 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+	expectEqual(t, "x,y", strings.Join(ls.Pending(), ","))
+
+	ls.Emit("x")
+	expectEqual(t, "y", strings.Join(ls.Pending(), ","))
+
+	ls.Emit("y")
+	<-done
+	expectEqual(t, 0, ls.PendingCount())
+}
+
+func TestLockStep_Drain(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	if err := ls.Drain(0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	go func() {
+		ls.Wait("x")
+	}()
+	for ls.PendingCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ls.Emit("x")
+	}()
+
+	if err := ls.Drain(time.Second); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestLockStep_DrainTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Wait("x")
+	}()
+	for ls.PendingCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := ls.Drain(100 * time.Millisecond); err == nil {
+		t.Fatalf("Expected error")
+	}
+
+	ls.Emit("x")
+}
+
+func TestLockStep_MustNotEmit(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Emit("y")
+	}()
+
+	begin := time.Now()
+	go ls.Wait("y")
+	ls.MustNotEmit("x", 100*time.Millisecond)
+	if dur := time.Since(begin); dur < 100*time.Millisecond {
+		t.Fatalf("Expected MustNotEmit to block, took %v", dur)
+	}
+}
+
+func TestLockStep_MustNotEmitFail(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		ls.Emit("x")
+	}()
+	go ls.Wait("x")
+
+	expectFail(t, func() {
+		ls.MustNotEmit("x", 5*time.Second)
+	})
+}
+
+func TestLockStep_Broadcast(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Broadcast("reload")
+		ls.Emit("done")
+	}()
+
+	ls.WaitN("reload", 3)
+	ls.Wait("done")
+}
+
+func TestLockStep_Broadcast_ConcurrentWaits(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithDoubleWaitPolicy(lockstep.Queue))
+
+	const n = 5
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			ls.Wait("reload")
+		}()
+	}
+	for ls.Statistics().WaitCount != n {
+		time.Sleep(time.Millisecond)
+	}
+
+	ls.Broadcast("reload")
+	wg.Wait()
+}
+
+func TestLockStep_BroadcastTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	expectFail(t, func() {
+		ls.Broadcast("reload")
+	})
+}
+
+func TestLockStep_Once_ReleasesEmit(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Emit("ready")
+	}()
+	for ls.Statistics().EmitCount == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	ls.Once("ready")
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Expected Once to release the blocked Emit")
+	}
+}
+
+func TestLockStep_Once(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.WaitN("ready", 2)
+	}()
+	for ls.PendingCount() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	ls.Once("ready")
+	<-done
+
+	// Future waits are satisfied immediately.
+	ls.Wait("ready")
+	expectEqual(t, "ready", ls.WaitAny("other", "ready"))
+	m, ok := ls.TryWait("ready")
+	expectEqual(t, true, ok)
+	expectEqual(t, "ready", m)
+	ls.Emit("ready")
+
+	ls.Reset()
+	_, ok = ls.TryWait("ready")
+	expectEqual(t, false, ok)
+}
+
+func TestLockStep_EmitAfter(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	const d = 100 * time.Millisecond
+	begin := time.Now()
+	cancel := ls.EmitAfter(d, "x")
+	defer cancel()
+
+	ls.Wait("x")
+	if dur := time.Since(begin); dur < d {
+		t.Fatalf("Expected emit after %v, actual was %v", d, dur)
+	}
+}
+
+func TestLockStep_EmitAfterCancel(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	cancel := ls.EmitAfter(50*time.Millisecond, "x")
+	cancel()
+
+	ls.MustNotEmit("x", 200*time.Millisecond)
+}
+
+func TestLockStep_EmitAfterCancelBlocked(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	cancel := ls.EmitAfter(0, "x")
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	// The abandoned emit does not satisfy future waits.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ls.Emit("y")
+	}()
+	expectEqual(t, "y", ls.WaitAny("x", "y"))
+}
+
+func TestLockStep_WaitForIdle(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	if err := ls.WaitForIdle(0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	go func() {
+		ls.Emit("x")
+	}()
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ls.Wait("x")
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := ls.WaitForIdle(time.Second); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestLockStep_WaitForIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Emit("x")
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	err := ls.WaitForIdle(100 * time.Millisecond)
+	if err == nil {
+		t.Fatalf("Expected error")
+	}
+	expectEqual(t, "timeout waiting for idle: still waiting for [], emitting [x]", err.Error())
+
+	ls.Wait("x")
+}
+
+func TestLockStep_Clone(t *testing.T) {
+	t.Parallel()
+
+	parent := lockstep.New(t, lockstep.WithFailureMode(lockstep.PanicMode))
+	parent.SetTimeout(100 * time.Millisecond)
+	var emits atomic.Int32
+	parent.OnEmit(func(string, time.Time) {
+		emits.Add(1)
+	})
+
+	go func() {
+		parent.Emit("x")
+	}()
+	parent.Wait("x")
+
+	// Hooks are cloned.
+	clone := parent.Clone(t)
+	go clone.Emit("y")
+	clone.Wait("y")
+	expectEqual(t, int32(2), emits.Load())
+
+	for _, m := range []string{"a", "b"} {
+		t.Run(m, func(t *testing.T) {
+			ls := parent.Clone(t)
+			expectEqual(t, 0, len(ls.History()))
+
+			defer func() {
+				if _, ok := recover().(*lockstep.LockStepError); !ok {
+					t.Fatalf("Expected configuration to be cloned")
+				}
+			}()
+			ls.Wait(m)
+		})
+	}
+}
+
+func TestLockStep_WaitWithin(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	go func() {
+		ls.Emit("x")
+	}()
+	ls.WaitWithin(time.Second, "x")
+
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.WaitWithin(100*time.Millisecond, "y")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected per-call timeout, took %v", dur)
+	}
+}
+
+func TestLockStep_EmitWithin(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+
+	go func() {
+		ls.Wait("x")
+	}()
+	ls.EmitWithin(time.Second, "x")
+
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.EmitWithin(100*time.Millisecond, "y")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected per-call timeout, took %v", dur)
+	}
+}
+
+func TestLockStep_SetPerMessageTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t})
+	ls.SetPerMessageTimeout("fast", 100*time.Millisecond)
+	ls.SetPerMessageTimeout("slow", 300*time.Millisecond)
+
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.Emit("fast")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected per-message timeout, took %v", dur)
+	}
+
+	begin = time.Now()
+	expectFail(t, func() {
+		ls.Wait("fast", "slow")
+	})
+	if dur := time.Since(begin); dur < 300*time.Millisecond || dur > time.Second {
+		t.Fatalf("Expected longest per-message timeout, took %v", dur)
+	}
+}
+
+func TestLockStep_WaitAnyOf(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	go func() {
+		ls.Emit("success-a")
+		ls.Emit("failure-x")
+		ls.Emit("failure-y")
+	}()
+	matched, i := ls.WaitAnyOf(
+		[]string{"success-a", "success-b"},
+		[]string{"failure-x", "failure-y"})
+	expectEqual(t, 1, i)
+	expectEqual(t, "failure-x, failure-y", strings.Join(matched, ", "))
+	expectEqual(t, 0, ls.PendingCount())
+
+	expectFail(t, func() {
+		ls.WaitAnyOf([]string{"a"}, []string{"b"})
+	})
+	expectEqual(t, 0, ls.PendingCount())
+}
+
+func TestLockStep_ConcurrentReads(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				ls.Pending()
+				ls.PendingCount()
+				ls.DumpState()
+				ls.History()
+			}
+		}()
+	}
+
+	for i := 0; i < 100; i++ {
+		go func() {
+			ls.Emit("x")
+		}()
+		ls.Wait("x")
+	}
+	close(done)
+	readers.Wait()
+}
+
+// TestLockStep_WaitGoroutines is not parallel because it counts all the
+// goroutines in the test binary.
+func TestLockStep_WaitGoroutines(t *testing.T) {
+	ls := lockstep.New(t)
+
+	const n = 100
+	before := runtime.NumGoroutine()
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			ls.Wait(fmt.Sprint(i))
+		}(i)
+	}
+	for ls.PendingCount() != n {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// One goroutine per Wait operation, and none per timeout.
+	if extra := runtime.NumGoroutine() - before; extra > n+n/10 {
+		t.Fatalf("Expected about %v goroutines, actual %v", n, extra)
+	}
+
+	for i := 0; i < n; i++ {
+		ls.Emit(fmt.Sprint(i))
+	}
+}
+
 func TestExample(t *testing.T) {
 	t.Parallel()
 
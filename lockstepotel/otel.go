@@ -0,0 +1,55 @@
+// Package lockstepotel traces LockStep operations with OpenTelemetry. It is a
+// separate module, so that lockstep itself has no dependencies.
+package lockstepotel
+
+import (
+	"context"
+
+	"github.com/dcaiafa/lockstep"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName is the name of the tracer used when WithOpenTelemetry
+// is called with a nil tracer.
+const instrumentationName = "github.com/dcaiafa/lockstep"
+
+// WithOpenTelemetry configures the LockStep to trace Emit and Wait operations
+// with tracer, as described in [lockstep.WithTracer]: each operation creates a
+// span named "lockstep.emit" or "lockstep.wait" with the attribute
+// "lockstep.message", and the span of a Wait operation is linked to the span
+// of the Emit operation it matched. If tracer is nil, a tracer from
+// otel.GetTracerProvider() is used.
+//
+//	ls := lockstep.New(t, lockstepotel.WithOpenTelemetry(nil))
+func WithOpenTelemetry(tracer trace.Tracer) lockstep.Option {
+	if tracer == nil {
+		tracer = otel.GetTracerProvider().Tracer(instrumentationName)
+	}
+	return lockstep.WithTracer(otelTracer{tracer})
+}
+
+// otelTracer adapts a trace.Tracer to lockstep.Tracer.
+type otelTracer struct {
+	tracer trace.Tracer
+}
+
+func (t otelTracer) Start(ctx context.Context, name, m string) lockstep.Span {
+	_, s := t.tracer.Start(ctx, name,
+		trace.WithAttributes(attribute.String("lockstep.message", m)))
+	return otelSpan{s}
+}
+
+// otelSpan adapts a trace.Span to lockstep.Span.
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) AddLink(to lockstep.Span) {
+	s.span.AddLink(trace.Link{SpanContext: to.(otelSpan).span.SpanContext()})
+}
+
+func (s otelSpan) End() {
+	s.span.End()
+}
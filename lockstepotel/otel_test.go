@@ -0,0 +1,72 @@
+package lockstepotel_test
+
+import (
+	"testing"
+
+	"github.com/dcaiafa/lockstep"
+	"github.com/dcaiafa/lockstep/lockstepotel"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// emitAndWait emits and waits for the message x with ls.
+func emitAndWait(ls *lockstep.LockStep) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+	<-done
+}
+
+// checkSpans checks that rec recorded an emit and a wait span for the message
+// x, and that the wait span is linked to the emit span.
+func checkSpans(t *testing.T, rec *tracetest.SpanRecorder) {
+	t.Helper()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, s := range rec.Ended() {
+		attrs := s.Attributes()
+		if len(attrs) != 1 || attrs[0] != attribute.String("lockstep.message", "x") {
+			t.Fatalf("Unexpected attributes of %v: %v", s.Name(), attrs)
+		}
+		spans[s.Name()] = s
+	}
+	wait, emit := spans["lockstep.wait"], spans["lockstep.emit"]
+	if len(spans) != 2 || wait == nil || emit == nil {
+		t.Fatalf("Unexpected spans: %v", spans)
+	}
+	links := wait.Links()
+	if len(links) != 1 || links[0].SpanContext.SpanID() != emit.SpanContext().SpanID() {
+		t.Fatalf("Expected wait span to link to emit span: %v", links)
+	}
+}
+
+func TestWithOpenTelemetry(t *testing.T) {
+	t.Parallel()
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	ls := lockstep.New(t, lockstepotel.WithOpenTelemetry(tp.Tracer("test")))
+	emitAndWait(ls)
+
+	checkSpans(t, rec)
+}
+
+// TestWithOpenTelemetry_Global is not parallel because it sets the global
+// tracer provider.
+func TestWithOpenTelemetry_Global(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	saved := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(saved)
+
+	ls := lockstep.New(t, lockstepotel.WithOpenTelemetry(nil))
+	emitAndWait(ls)
+
+	checkSpans(t, rec)
+}
@@ -0,0 +1,110 @@
+package lockstep
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// SetOutput redirects verbose logs to w instead of t.Logf. Each line is
+// prefixed with a timestamp and the ID of the goroutine that logged it.
+// Passing nil restores logging with t.Logf.
+func (l *LockStep) SetOutput(w io.Writer) {
+	l.outputMu.Lock()
+	defer l.outputMu.Unlock()
+
+	l.output = w
+}
+
+// SetLogger configures verbose logs to be written as structured records to h
+// instead of t.Logf. Each [Event] is logged at slog.LevelInfo with the
+// attributes op, message, goroutine and elapsed. Other diagnostic messages are
+// logged at slog.LevelDebug. Passing nil restores the default behavior.
+func (l *LockStep) SetLogger(h slog.Handler) {
+	l.outputMu.Lock()
+	defer l.outputMu.Unlock()
+
+	l.logger = h
+}
+
+func (l *LockStep) logf(msg string, args ...any) {
+	if !l.verbose {
+		return
+	}
+
+	// Identify the goroutine responsible for the log line, so it can be
+	// correlated with goroutine traces.
+	g := goroutineID()
+	text := fmt.Sprintf(msg, args...)
+
+	l.outputMu.Lock()
+	defer l.outputMu.Unlock()
+
+	switch {
+	case l.logger != nil:
+		l.logRecord(slog.LevelDebug, l.namePrefix()+text, slog.Uint64("goroutine", g))
+	case l.output != nil:
+		ts := time.Now().Format("15:04:05.000000")
+		fmt.Fprintf(l.output, "%v [g%v] %v%v\n", ts, g, l.namePrefix(), l.colorize(l.output, text))
+	default:
+		// t.Logf output ends up in the test binary's stdout.
+		l.t.Logf("[g%v] %v%v", g, l.namePrefix(), l.colorize(os.Stdout, text))
+	}
+}
+
+// namePrefix returns the prefix for the log lines of the instance, based on
+// its name.
+func (l *LockStep) namePrefix() string {
+	if l.name == "" {
+		return ""
+	}
+	return "[" + l.name + "] "
+}
+
+// logEvent logs e to the structured logger, if any. Otherwise, only timeouts
+// are logged, since other events are already described by logf.
+func (l *LockStep) logEvent(e Event) {
+	if !l.verbose {
+		return
+	}
+
+	if e.Op == OpTimeout && !l.hasLogger() {
+		l.logf("Timed out %v after %v", e.Message, e.Duration)
+		return
+	}
+
+	l.outputMu.Lock()
+	defer l.outputMu.Unlock()
+
+	if l.logger == nil {
+		return
+	}
+
+	l.logRecord(slog.LevelInfo, "lockstep "+string(e.Op),
+		slog.String("op", string(e.Op)),
+		slog.String("message", e.Message),
+		slog.Uint64("goroutine", e.Goroutine),
+		slog.Duration("elapsed", e.Duration))
+}
+
+func (l *LockStep) hasLogger() bool {
+	l.outputMu.Lock()
+	defer l.outputMu.Unlock()
+
+	return l.logger != nil
+}
+
+// logRecord writes a record to l.logger. l.outputMu must be held.
+func (l *LockStep) logRecord(level slog.Level, msg string, attrs ...slog.Attr) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	r := slog.NewRecord(time.Now(), level, msg, 0)
+	r.AddAttrs(attrs...)
+	l.logger.Handle(ctx, r)
+}
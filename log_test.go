@@ -0,0 +1,116 @@
+package lockstep_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_SetOutput(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	ls := lockstep.New(t, lockstep.WithVerbose(true))
+	ls.SetOutput(&buf)
+
+	ls.TryEmit("x")
+
+	line := buf.String()
+	if !regexp.MustCompile(`^\d\d:\d\d:\d\d\.\d{6} \[g\d+\] Not emitted x\n$`).MatchString(line) {
+		t.Fatalf("Unexpected output: %q", line)
+	}
+}
+
+func TestLockStep_VerboseGoroutineID(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r, lockstep.WithVerbose(true))
+
+	ls.TryEmit("x")
+
+	logs := r.Logs()
+	if len(logs) != 1 || !regexp.MustCompile(`^\[g\d+\] Not emitted x$`).MatchString(logs[0]) {
+		t.Fatalf("Unexpected logs: %q", logs)
+	}
+}
+
+// recordHandler is a slog.Handler that stores the records it handles.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool { return true }
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler       { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler            { return h }
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func TestLockStep_SetLogger(t *testing.T) {
+	t.Parallel()
+
+	h := &recordHandler{}
+	ls := lockstep.New(t, lockstep.WithVerbose(true))
+	ls.SetLogger(h)
+
+	go func() {
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var ops []string
+	for _, r := range h.records {
+		if r.Level != slog.LevelInfo {
+			continue
+		}
+		attrs := make(map[string]slog.Value)
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		expectEqual(t, "x", attrs["message"].String())
+		if attrs["goroutine"].Uint64() == 0 {
+			t.Fatalf("Expected goroutine attribute")
+		}
+		ops = append(ops, attrs["op"].String())
+	}
+	if len(ops) != 3 || ops[2] != "match" {
+		t.Fatalf("Unexpected events: %v", ops)
+	}
+}
+
+func TestWithColor(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		opts []lockstep.Option
+		want string
+	}{
+		// A bytes.Buffer is not a terminal.
+		{nil, " Latching x\n"},
+		{[]lockstep.Option{lockstep.WithColor(false)}, " Latching x\n"},
+		{[]lockstep.Option{lockstep.WithColor(true)}, " \x1b[36mLatching x\x1b[0m\n"},
+	} {
+		var buf bytes.Buffer
+		ls := lockstep.New(t, append(tc.opts, lockstep.WithVerbose(true))...)
+		ls.SetOutput(&buf)
+		ls.Once("x")
+		if !bytes.HasSuffix(buf.Bytes(), []byte(tc.want)) {
+			t.Fatalf("Unexpected output: %q", buf.String())
+		}
+	}
+}
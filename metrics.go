@@ -0,0 +1,92 @@
+package lockstep
+
+// Counter is a metric that can only be incremented. See
+// [MetricsRegisterer].
+type Counter interface {
+	Inc()
+}
+
+// Histogram is a metric that samples observations. See [MetricsRegisterer].
+type Histogram interface {
+	Observe(v float64)
+}
+
+// MetricsRegisterer creates and registers the metrics of LockStep instances.
+// See [WithPrometheusRegistry].
+type MetricsRegisterer interface {
+	// NewCounter creates and registers a counter with the given name, help
+	// text and constant labels.
+	NewCounter(name, help string, labels map[string]string) Counter
+	// NewHistogram creates and registers a histogram with the given name,
+	// help text and constant labels.
+	NewHistogram(name, help string, labels map[string]string) Histogram
+}
+
+// WithPrometheusRegistry configures the LockStep to export metrics through r:
+// the counters lockstep_emits_total, lockstep_waits_total and
+// lockstep_matches_total, and the histogram lockstep_match_latency_seconds
+// with the Duration of OpMatch events. All of them have the label "name" set
+// to the name of the instance (see [WithName]).
+//
+// To keep LockStep free of dependencies, r is an interface. An adapter for a
+// prometheus.Registerer looks like this:
+//
+//	type promRegisterer struct{ prometheus.Registerer }
+//
+//	func (r promRegisterer) NewCounter(name, help string, labels map[string]string) lockstep.Counter {
+//		return register(r, prometheus.NewCounter(prometheus.CounterOpts{
+//			Name: name, Help: help, ConstLabels: labels}))
+//	}
+//
+//	func (r promRegisterer) NewHistogram(name, help string, labels map[string]string) lockstep.Histogram {
+//		return register(r, prometheus.NewHistogram(prometheus.HistogramOpts{
+//			Name: name, Help: help, ConstLabels: labels}))
+//	}
+//
+//	// register registers c, or returns the existing collector if an instance
+//	// with the same name already registered it.
+//	func register[C prometheus.Collector](r prometheus.Registerer, c C) C {
+//		if err := r.Register(c); err != nil {
+//			are, ok := err.(prometheus.AlreadyRegisteredError)
+//			if !ok {
+//				panic(err)
+//			}
+//			return are.ExistingCollector.(C)
+//		}
+//		return c
+//	}
+func WithPrometheusRegistry(r MetricsRegisterer) Option {
+	return func(l *LockStep) {
+		l.registerer = r
+	}
+}
+
+// metrics holds the metrics created with the MetricsRegisterer.
+type metrics struct {
+	emits   Counter
+	waits   Counter
+	matches Counter
+	latency Histogram
+}
+
+func newMetrics(r MetricsRegisterer, name string) *metrics {
+	labels := map[string]string{"name": name}
+	return &metrics{
+		emits:   r.NewCounter("lockstep_emits_total", "Number of Emit operations started.", labels),
+		waits:   r.NewCounter("lockstep_waits_total", "Number of messages registered by Wait operations.", labels),
+		matches: r.NewCounter("lockstep_matches_total", "Number of Emit operations matched with Wait operations.", labels),
+		latency: r.NewHistogram("lockstep_match_latency_seconds", "Time to match Emit and Wait operations.", labels),
+	}
+}
+
+func (m *metrics) add(e Event) {
+	switch e.Op {
+	case OpEmit:
+		m.emits.Inc()
+	case OpWait:
+		m.waits.Inc()
+	case OpMatch:
+		m.matches.Inc()
+		m.latency.Observe(e.Duration.Seconds())
+	}
+}
@@ -0,0 +1,61 @@
+package lockstep_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+// fakeRegistry is a lockstep.MetricsRegisterer that keeps the values of the
+// metrics by name.
+type fakeRegistry struct {
+	mu     sync.Mutex
+	values map[string]float64
+	labels map[string]string
+}
+
+type fakeMetric struct {
+	r    *fakeRegistry
+	name string
+}
+
+func (m fakeMetric) Inc() {
+	m.Observe(1)
+}
+
+func (m fakeMetric) Observe(v float64) {
+	m.r.mu.Lock()
+	defer m.r.mu.Unlock()
+	m.r.values[m.name] += v
+}
+
+func (r *fakeRegistry) NewCounter(name, _ string, labels map[string]string) lockstep.Counter {
+	r.labels = labels
+	return fakeMetric{r, name}
+}
+
+func (r *fakeRegistry) NewHistogram(name, _ string, labels map[string]string) lockstep.Histogram {
+	return fakeMetric{r, name}
+}
+
+func TestWithPrometheusRegistry(t *testing.T) {
+	t.Parallel()
+
+	r := &fakeRegistry{values: make(map[string]float64)}
+	ls := lockstep.NewNamed(t, "db", lockstep.WithPrometheusRegistry(r))
+
+	go ls.Emit("x")
+	ls.Wait("x")
+	ls.TryEmit("y")
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expectEqual(t, "db", r.labels["name"])
+	expectEqual(t, 1.0, r.values["lockstep_emits_total"])
+	expectEqual(t, 1.0, r.values["lockstep_waits_total"])
+	expectEqual(t, 1.0, r.values["lockstep_matches_total"])
+	if r.values["lockstep_match_latency_seconds"] <= 0 {
+		t.Fatalf("Expected match latency to be observed")
+	}
+}
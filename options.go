@@ -0,0 +1,68 @@
+package lockstep
+
+import "time"
+
+// Option configures a [LockStep] instance. Options are passed to [New].
+type Option func(*LockStep)
+
+// WithTimeout overrides [DefaultTimeout] for Emit and Wait operations. It is
+// equivalent to calling [LockStep.SetTimeout].
+func WithTimeout(d time.Duration) Option {
+	return func(l *LockStep) {
+		l.timeout = d
+	}
+}
+
+// WithVerbose configures verbose mode. It is equivalent to calling
+// [LockStep.SetVerbose].
+func WithVerbose(v bool) Option {
+	return func(l *LockStep) {
+		l.verbose = v
+	}
+}
+
+// WithFailureMode configures how failures are reported. The default is
+// [FatalMode].
+func WithFailureMode(m FailureMode) Option {
+	return func(l *LockStep) {
+		l.failureMode = m
+	}
+}
+
+// WithCleanup configures the LockStep to register a t.Cleanup function that
+// reports, using t.Errorf, any Wait operations still pending when the test
+// finishes.
+func WithCleanup(v bool) Option {
+	return func(l *LockStep) {
+		l.cleanup = v
+	}
+}
+
+// WithDeadlineFromTest configures the LockStep to never let an operation run
+// past the test's deadline, as reported by t.Deadline. This way, when the test
+// binary is about to time out, the pending operation fails with a descriptive
+// message. It has no effect if the test has no deadline.
+func WithDeadlineFromTest(v bool) Option {
+	return func(l *LockStep) {
+		l.deadlineFromTest = v
+	}
+}
+
+// WithSoftTimeout configures the LockStep to log a warning, using t.Logf, for
+// each Emit or Wait operation still blocked after d. The operation continues
+// until it completes or the regular timeout expires. This helps tell apart
+// tests that are merely slow from tests that are stuck.
+func WithSoftTimeout(d time.Duration) Option {
+	return func(l *LockStep) {
+		l.softTimeout = d
+	}
+}
+
+// WithName labels the LockStep instance, so that its verbose logs can be told
+// apart from those of other instances in the same test. Log lines are prefixed
+// with the name in brackets, e.g. "[db]".
+func WithName(name string) Option {
+	return func(l *LockStep) {
+		l.name = name
+	}
+}
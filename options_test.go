@@ -0,0 +1,138 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestWithTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.Wait("x")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected timeout to be overridden, took %v", dur)
+	}
+}
+
+func TestWithVerbose(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t, lockstep.WithVerbose(true))
+
+	go func() {
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+}
+
+func TestWithCleanup(t *testing.T) {
+	t.Parallel()
+
+	var r *Recorder
+	var ls *lockstep.LockStep
+	t.Run("sub", func(t *testing.T) {
+		r = &Recorder{T: t}
+		ls = lockstep.New(r, lockstep.WithCleanup(true))
+
+		go func() {
+			ls.Wait("x", "y")
+		}()
+		for ls.PendingCount() == 0 {
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	errs := r.Errors()
+	expectEqual(t, 1, len(errs))
+	expectEqual(t, "Abandoned wait for x, y", errs[0])
+
+	ls.Emit("x")
+	ls.Emit("y")
+}
+
+// DeadlineT is a test context with a custom deadline.
+type DeadlineT struct {
+	*PanicFailer
+	deadline time.Time
+}
+
+func (t *DeadlineT) Deadline() (time.Time, bool) {
+	return t.deadline, !t.deadline.IsZero()
+}
+
+func TestWithDeadlineFromTest(t *testing.T) {
+	t.Parallel()
+
+	dt := &DeadlineT{
+		PanicFailer: &PanicFailer{T: t},
+		deadline:    time.Now().Add(100 * time.Millisecond),
+	}
+	ls := lockstep.New(dt, lockstep.WithDeadlineFromTest(true))
+
+	begin := time.Now()
+	expectFail(t, func() {
+		ls.Wait("x")
+	})
+	if dur := time.Since(begin); dur > time.Second {
+		t.Fatalf("Expected test deadline to win, took %v", dur)
+	}
+}
+
+func TestWithDeadlineFromTest_NoDeadline(t *testing.T) {
+	t.Parallel()
+
+	dt := &DeadlineT{PanicFailer: &PanicFailer{T: t}}
+	ls := lockstep.New(dt,
+		lockstep.WithDeadlineFromTest(true),
+		lockstep.WithTimeout(100*time.Millisecond))
+
+	expectFail(t, func() {
+		ls.Wait("x")
+	})
+}
+
+func TestWithSoftTimeout(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r, lockstep.WithSoftTimeout(50*time.Millisecond))
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+
+	logs := r.Logs()
+	expectEqual(t, 1, len(logs))
+	expectEqual(t, "soft timeout: still waiting for x after 50ms", logs[0])
+}
+
+func TestWithName(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.NewNamed(r, "db", lockstep.WithVerbose(true), lockstep.WithColor(false))
+	expectEqual(t, "db", ls.Name())
+	expectEqual(t, "db", ls.Clone(t).Name())
+	expectEqual(t, "", lockstep.New(t).Name())
+
+	ls.TryEmit("x")
+	logs := r.Logs()
+	expectEqual(t, 1, len(logs))
+	if !strings.HasSuffix(logs[0], "] [db] Not emitted x") {
+		t.Fatalf("Unexpected log: %q", logs[0])
+	}
+
+	if !strings.HasPrefix(ls.DumpState(), "LockStep [db] state at ") {
+		t.Fatalf("Unexpected state: %q", ls.DumpState())
+	}
+}
@@ -0,0 +1,155 @@
+package lockstep
+
+import (
+	"context"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WaitPrefix waits for any message starting with prefix, and returns the
+// message emitted. Each concurrent WaitPrefix operation is satisfied by a
+// different Emit operation.
+//
+//	for i := 0; i < n; i++ {
+//		m := ls.WaitPrefix("worker-")
+//		// m == "worker-3-done", ...
+//	}
+//
+// Wait operations for the exact message take precedence. If the operation
+// fails and the failure mode allows it to return, WaitPrefix returns "".
+func (l *LockStep) WaitPrefix(prefix string) string {
+	l.t.Helper()
+
+	return l.waitMatching("prefix "+l.qualify(prefix)+"*", func(m string) bool {
+		return strings.HasPrefix(m, prefix)
+	})
+}
+
+// WaitGlob waits for any message matching pattern, using the syntax of
+// [path.Match], and returns the message emitted. For example,
+// WaitGlob("stage/*/complete") matches "stage/1/complete". Like WaitPrefix,
+// each concurrent WaitGlob operation is satisfied by a different Emit
+// operation. If the operation fails and the failure mode allows it to return,
+// WaitGlob returns "".
+func (l *LockStep) WaitGlob(pattern string) string {
+	l.t.Helper()
+
+	if _, err := path.Match(pattern, ""); err != nil {
+		l.fail(newError(OpWait, pattern, "bad pattern", "Bad pattern %v: %v", pattern, err))
+		return ""
+	}
+
+	return l.waitMatching("glob "+l.qualify(pattern), func(m string) bool {
+		ok, _ := path.Match(pattern, m)
+		return ok
+	})
+}
+
+// WaitFunc waits for any message for which predicate returns true, and
+// returns the message emitted. It is the most general form of pattern-based
+// matching:
+//
+//	m := ls.WaitFunc(func(m string) bool {
+//		return strings.HasSuffix(m, "-done")
+//	})
+//
+// predicate is called with l's lock held, so it must not call any methods of
+// l. Like WaitPrefix, each concurrent WaitFunc operation is satisfied by a
+// different Emit operation. If the operation fails and the failure mode allows
+// it to return, WaitFunc returns "".
+func (l *LockStep) WaitFunc(predicate func(m string) bool) string {
+	l.t.Helper()
+
+	return l.waitMatching("func", predicate)
+}
+
+// waitMatching waits for any message of l for which match returns true, and
+// returns it. desc describes the messages matched, for logging and errors.
+func (l *LockStep) waitMatching(desc string, match func(m string) bool) string {
+	l.t.Helper()
+
+	if l.prefix != "" {
+		unqualified := match
+		match = func(m string) bool {
+			return strings.HasPrefix(m, l.prefix) && unqualified(l.unqualify(m))
+		}
+	}
+
+	m, err := l.awaitPattern(desc, match)
+	if err != nil {
+		l.fail(err)
+		return ""
+	}
+	return l.unqualify(m)
+}
+
+// awaitPattern waits for any message for which match returns true, and
+// returns it. Unlike waitMatching, it returns failures, and messages are not
+// qualified.
+func (l *LockStep) awaitPattern(desc string, match func(m string) bool) (string, *LockStepError) {
+	l.t.Helper()
+
+	l.logf("Waiting for %v", desc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.opTimeout())
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w := newWaiter(true)
+	w.match = match
+	w.desc = desc
+	w.start = time.Now()
+	l.patterns = append(l.patterns, w)
+	l.recordWithLock(OpWait, desc, 0)
+	defer l.removePatternWithLock(w)
+
+	// Latched and buffered messages are satisfied right away.
+	for _, m := range slices.Sorted(maps.Keys(l.latched)) {
+		if len(w.matched) == 0 && match(m) {
+			l.matchWithLock(m, w.start, nil, nil)
+		}
+	}
+	for _, m := range slices.Sorted(maps.Keys(l.buffered)) {
+		if len(w.matched) == 0 && match(m) {
+			l.consumeBufferedWithLock(m, w.start)
+		}
+	}
+
+	l.broadcastWithLock()
+
+	defer l.startSoftTimeoutWithLock(func() string {
+		return "waiting for " + desc
+	})()
+
+	for len(w.matched) == 0 {
+		if !l.waitWithLock(ctx) {
+			l.recordTimeoutWithLock(OpWait, desc, time.Since(w.start))
+			return "", l.ctxError(ctx, OpWait, desc, "waiting for %v", desc)
+		}
+	}
+	return w.matched[0], nil
+}
+
+// patternWaiterWithLock returns the oldest pattern waiter that matches m, or
+// nil. l.mu must be held.
+func (l *LockStep) patternWaiterWithLock(m string) *waiter {
+	for _, w := range l.patterns {
+		if w.match(m) {
+			return w
+		}
+	}
+	return nil
+}
+
+// removePatternWithLock unregisters the pattern waiter w. l.mu must be held.
+func (l *LockStep) removePatternWithLock(w *waiter) {
+	l.patterns = slices.DeleteFunc(l.patterns, func(pw *waiter) bool {
+		return pw == w
+	})
+	l.broadcastWithLock()
+}
@@ -0,0 +1,99 @@
+package lockstep_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_WaitPrefix(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		go ls.Emit(fmt.Sprintf("worker-%d-done", i))
+	}
+
+	got := make(chan string, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			got <- ls.WaitPrefix("worker-")
+		}()
+	}
+
+	seen := make(map[string]bool)
+	for i := 0; i < 3; i++ {
+		seen[<-got] = true
+	}
+	for i := 0; i < 3; i++ {
+		expectEqual(t, true, seen[fmt.Sprintf("worker-%d-done", i)])
+	}
+
+	ls.Once("worker-latched")
+	expectEqual(t, "worker-latched", ls.WaitPrefix("worker-"))
+
+	ls.Once("other")
+	expectFail(t, func() {
+		ls.WaitPrefix("none-")
+	})
+}
+
+func TestLockStep_WaitPrefix_Pending(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	done := make(chan struct{})
+	go func() {
+		ls.WaitPrefix("worker-")
+		close(done)
+	}()
+	for ls.PendingCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	expectEqual(t, "prefix worker-*", ls.Pending()[0])
+
+	expectEqual(t, true, ls.TryEmit("worker-1"))
+	<-done
+	expectEqual(t, 0, ls.PendingCount())
+}
+
+func TestLockStep_WaitGlob(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	go ls.Emit("stage/1/complete")
+	expectEqual(t, "stage/1/complete", ls.WaitGlob("stage/*/complete"))
+
+	ls.Once("stage/1/2/complete")
+	expectFail(t, func() {
+		ls.WaitGlob("stage/*/complete")
+	})
+
+	expectFail(t, func() {
+		ls.WaitGlob("stage/[")
+	})
+}
+
+func TestLockStep_WaitFunc(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	hasDoneSuffix := func(m string) bool {
+		return strings.HasSuffix(m, "-done")
+	}
+
+	go ls.Emit("job-done")
+	expectEqual(t, "job-done", ls.WaitFunc(hasDoneSuffix))
+
+	ls.Once("job-started")
+	expectFail(t, func() {
+		ls.WaitFunc(hasDoneSuffix)
+	})
+}
@@ -0,0 +1,44 @@
+package lockstep
+
+// DuplicatePolicy determines what happens when an operation starts while
+// another one of the same kind for the same message is still blocked. See
+// [WithDoubleEmitPolicy] and [WithDoubleWaitPolicy].
+type DuplicatePolicy int
+
+const (
+	// Queue lets duplicate operations block, and matches them in FIFO order.
+	Queue DuplicatePolicy = iota
+	// Fail fails the duplicate operation immediately.
+	Fail
+)
+
+// WithDoubleEmitPolicy configures what happens when an Emit operation for a
+// message starts while another one for the same message is still blocked. The
+// default is [Queue]: each subsequent Wait operation for the message matches
+// the oldest blocked Emit operation.
+func WithDoubleEmitPolicy(p DuplicatePolicy) Option {
+	return func(l *LockStep) {
+		l.doubleEmit = p
+	}
+}
+
+// WithDoubleWaitPolicy configures what happens when a Wait operation for a
+// message starts while another one for the same message is still blocked. The
+// default is [Fail], which reports a double wait. With [Queue], multiple Wait
+// operations, e.g. from several consumers of a pub-sub system, can wait for
+// the same message; each needs its own Emit operation, unless it is emitted
+// with [LockStep.Broadcast], and they are satisfied in FIFO order.
+func WithDoubleWaitPolicy(p DuplicatePolicy) Option {
+	return func(l *LockStep) {
+		l.doubleWait = p
+	}
+}
+
+// checkDoubleEmitWithLock returns an error if an Emit operation for m is not
+// allowed to start because of the double emit policy. l.mu must be held.
+func (l *LockStep) checkDoubleEmitWithLock(m string) *LockStepError {
+	if l.doubleEmit == Fail && l.emitting[m] > 0 {
+		return newError(OpEmit, m, "double emit", "Double emit for %v", m)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_DoubleEmitPolicy_Queue(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	for i := 0; i < 3; i++ {
+		go ls.EmitValue("x", i)
+		// Let the Emit operation start before starting the next one.
+		for ls.Statistics().EmitCount != int64(i+1) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		expectEqual(t, i, lockstep.Wait[int](ls, "x"))
+	}
+}
+
+func TestLockStep_DoubleEmitPolicy_Fail(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t},
+		lockstep.WithTimeout(time.Second),
+		lockstep.WithDoubleEmitPolicy(lockstep.Fail))
+
+	go ls.Emit("x")
+	for ls.Statistics().EmitCount != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	expectFail(t, func() {
+		ls.Emit("x")
+	})
+	ls.Wait("x")
+
+	// Sequential emits are fine.
+	go ls.Wait("x")
+	ls.Emit("x")
+}
+
+func TestLockStep_DoubleWaitPolicy_Fail(t *testing.T) {
+	t.Parallel()
+
+	// Fail is the default.
+	ls := lockstep.New(t, lockstep.WithTimeout(10*time.Second))
+
+	go ls.Wait("x")
+	for ls.Statistics().WaitCount != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The second Wait operation fails without waiting for the timeout.
+	start := time.Now()
+	err := ls.WaitE("x")
+	if err == nil || err.Error() != "Double wait for x" {
+		t.Fatalf("Expected double wait, actual %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Expected immediate failure, took %v", elapsed)
+	}
+
+	ls.Emit("x")
+}
+
+func TestLockStep_DoubleWaitPolicy_Queue(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t},
+		lockstep.WithTimeout(time.Second),
+		lockstep.WithDoubleWaitPolicy(lockstep.Queue))
+
+	got := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		go func(i int) {
+			ls.Wait("x")
+			got <- i
+		}(i)
+		for ls.Statistics().WaitCount != int64(i+1) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		ls.Emit("x")
+		expectEqual(t, i, <-got)
+	}
+	expectEqual(t, 0, ls.PendingCount())
+}
+
+func TestLockStep_DoubleWaitPolicy_QueueTimeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t},
+		lockstep.WithTimeout(time.Second),
+		lockstep.WithDoubleWaitPolicy(lockstep.Queue))
+
+	done := make(chan struct{})
+	go func() {
+		ls.Wait("x")
+		close(done)
+	}()
+	for ls.Statistics().WaitCount != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// A queued waiter that times out leaves the queue.
+	expectFail(t, func() {
+		ls.WaitWithin(10*time.Millisecond, "x")
+	})
+
+	ls.Emit("x")
+	<-done
+	expectEqual(t, 0, ls.PendingCount())
+}
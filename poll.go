@@ -0,0 +1,92 @@
+package lockstep
+
+import (
+	"reflect"
+	"runtime"
+	"time"
+)
+
+// Eventually polls f every interval until it returns true, and fails if it
+// does not do so within timeout. interval must be positive. A timeout of zero
+// means the timeout configured for the LockStep instance. Like Emit and Wait
+// operations, the polling is logged in verbose mode, and a timeout is reported
+// according to the failure mode and handler.
+//
+//	ls.Eventually(func() bool { return cache.Len() == 0 }, 10*time.Millisecond, 0)
+func (l *LockStep) Eventually(f func() bool, interval, timeout time.Duration) {
+	l.t.Helper()
+
+	if timeout == 0 {
+		timeout = l.opTimeout()
+	} else {
+		timeout = l.capTimeout(timeout)
+	}
+
+	name := funcName(f)
+	if interval <= 0 {
+		l.fail(newError(
+			"eventually", name, "invalid interval",
+			"Invalid interval %v polling %v: must be positive", interval, name))
+		return
+	}
+	l.logf("Polling %v for up to %v", name, timeout)
+
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for !f() {
+		if time.Since(start) >= timeout {
+			l.fail(newError(
+				"eventually", name, "timeout",
+				"Timeout waiting for %v to be true after %v", name, time.Since(start)))
+			return
+		}
+		<-ticker.C
+	}
+
+	l.logf("Condition %v satisfied after %v", name, time.Since(start))
+}
+
+// Consistently polls f every interval for duration, and fails as soon as it
+// returns false. interval must be positive. Failures are reported according to
+// the failure mode and handler.
+//
+//	ls.Consistently(func() bool { return pool.Size() <= max }, time.Millisecond, 100*time.Millisecond)
+func (l *LockStep) Consistently(f func() bool, interval, duration time.Duration) {
+	l.t.Helper()
+
+	name := funcName(f)
+	if interval <= 0 {
+		l.fail(newError(
+			"consistently", name, "invalid interval",
+			"Invalid interval %v polling %v: must be positive", interval, name))
+		return
+	}
+	l.logf("Checking %v for %v", name, duration)
+
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if !f() {
+			l.fail(newError(
+				"consistently", name, "condition false",
+				"Condition %v became false after %v", name, time.Since(start)))
+			return
+		}
+		if time.Since(start) >= duration {
+			break
+		}
+		<-ticker.C
+	}
+
+	l.logf("Condition %v held for %v", name, duration)
+}
+
+// funcName returns the name of the function f, for use in messages.
+func funcName(f any) string {
+	if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return "condition"
+}
@@ -0,0 +1,61 @@
+package lockstep_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_Eventually(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	var ready atomic.Bool
+	time.AfterFunc(20*time.Millisecond, func() { ready.Store(true) })
+	ls.Eventually(ready.Load, time.Millisecond, 0)
+
+	expectFail(t, func() {
+		ls.Eventually(func() bool { return false }, time.Millisecond, 20*time.Millisecond)
+	})
+}
+
+func TestLockStep_Consistently(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	start := time.Now()
+	ls.Consistently(func() bool { return true }, time.Millisecond, 20*time.Millisecond)
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Fatalf("Consistently returned after %v", d)
+	}
+
+	var broken atomic.Bool
+	time.AfterFunc(10*time.Millisecond, func() { broken.Store(true) })
+	expectFail(t, func() {
+		ls.Consistently(func() bool { return !broken.Load() }, time.Millisecond, time.Second)
+	})
+}
+
+func TestLockStep_Eventually_InvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	expectFail(t, func() {
+		ls.Eventually(func() bool { return true }, 0, 0)
+	})
+}
+
+func TestLockStep_Consistently_InvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	expectFail(t, func() {
+		ls.Consistently(func() bool { return true }, -time.Millisecond, time.Second)
+	})
+}
@@ -0,0 +1,351 @@
+package lockstep
+
+import (
+	"context"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReplayTolerance is the default value of [Recording.Tolerance].
+const DefaultReplayTolerance = 50 * time.Millisecond
+
+// replayPollInterval is how often Replay checks for the next event.
+const replayPollInterval = time.Millisecond
+
+// RecordedEvent is an entry in a [Recording].
+type RecordedEvent struct {
+	Op      Op     `json:"op"`
+	Message string `json:"message"`
+
+	// Call is the LockStep method that started the operation, e.g. "Wait"
+	// or "Broadcast". It is only set for OpEmit and OpWait events.
+	Call string `json:"call,omitempty"`
+
+	// Goroutine is the ID of the goroutine that caused the event.
+	Goroutine uint64 `json:"goroutine,omitempty"`
+
+	// Offset is the time elapsed between the start of the recording and the
+	// event.
+	Offset time.Duration `json:"offset"`
+
+	// Duration is the Duration of the corresponding [Event].
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Recording is a sequence of events captured from a LockStep instance, which
+// can be replayed later with [LockStep.Replay]. A Recording can be serialized
+// as JSON, e.g. to store it in a golden file.
+type Recording struct {
+	Events []RecordedEvent `json:"events"`
+
+	// Tolerance is the maximum difference allowed by Replay between the
+	// offsets and durations of the recorded events and those of the replayed
+	// ones. If zero, DefaultReplayTolerance is used.
+	Tolerance time.Duration `json:"tolerance,omitempty"`
+
+	l     *LockStep
+	start time.Time
+}
+
+// Record starts capturing the events of l, until Stop is called on the
+// returned Recording. Messages are recorded with their full names (see
+// [LockStep.Child]).
+//
+//	rec := ls.Record()
+//	runScenario(ls)
+//	rec.Stop()
+//	data, err := json.Marshal(rec)
+func (l *LockStep) Record() *Recording {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := &Recording{l: l, start: time.Now()}
+	l.recordings = append(l.recordings, r)
+	return r
+}
+
+// Stop stops capturing events. Events must not be accessed before Stop is
+// called. Calling Stop more than once, or on a Recording that was
+// deserialized, has no effect.
+func (r *Recording) Stop() {
+	if r.l == nil {
+		return
+	}
+
+	r.l.mu.Lock()
+	defer r.l.mu.Unlock()
+
+	r.l.recordings = slices.DeleteFunc(r.l.recordings, func(o *Recording) bool {
+		return o == r
+	})
+	r.l = nil
+}
+
+// add appends e to the recording. r.l.mu must be held.
+func (r *Recording) add(e Event) {
+	re := RecordedEvent{
+		Op:        e.Op,
+		Message:   e.Message,
+		Goroutine: e.Goroutine,
+		Offset:    e.Time.Sub(r.start),
+		Duration:  e.Duration,
+	}
+	if e.Op == OpEmit || e.Op == OpWait {
+		re.Call = operationName()
+	}
+	r.Events = append(r.Events, re)
+}
+
+// operationName returns the name of the outermost LockStep method in the call
+// stack, e.g. "Wait", or "EmitAfter" for the goroutine started by EmitAfter.
+func operationName() string {
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	name := ""
+	for {
+		f, more := frames.Next()
+		if method, ok := strings.CutPrefix(f.Function, packagePrefix+"(*LockStep)."); ok {
+			name, _, _ = strings.Cut(method, ".")
+		}
+		if !more {
+			return name
+		}
+	}
+}
+
+// replayStep is an operation of a Recording, started by the event at index
+// first of the recording.
+type replayStep struct {
+	first int
+	call  string
+	op    Op
+	ms    []string
+}
+
+// Replay drives l through the scenario captured in r, and verifies that it
+// produces the same events, in the same order, with the same timing.
+//
+// The recorded events are replayed in order: each recorded Emit and Wait
+// operation is started, from its own goroutine if it blocks, at the same
+// offset as in the recording, and Replay waits for l to produce each event
+// before moving on to the next one. This makes the replay deterministic: for
+// example, the recorded matches act as barriers for the operations that follow
+// them. Replay fails if an event differs from the recorded one, or if its
+// offset or duration differs by more than r.Tolerance. In that case, l is
+// cancelled (see [LockStep.Cancel]) to release the operations in progress.
+// Otherwise, Replay returns once all the operations are done.
+//
+// Emit operations are replayed without their values and contexts. WaitFunc,
+// WaitN and the other operations that can't be reproduced from their events,
+// such as Rendezvous, are not supported: Replay fails if r contains them.
+//
+//	var rec lockstep.Recording
+//	json.Unmarshal(golden, &rec)
+//	lockstep.New(t).Replay(&rec)
+func (l *LockStep) Replay(r *Recording) {
+	l.t.Helper()
+
+	steps, err := replaySteps(r.Events)
+	if err != nil {
+		l.fail(err)
+		return
+	}
+
+	tolerance := r.Tolerance
+	if tolerance == 0 {
+		tolerance = DefaultReplayTolerance
+	}
+
+	replayed := l.Record()
+	start := replayed.start
+
+	var wg sync.WaitGroup
+	errs := make(chan *LockStepError, len(steps))
+	err = func() *LockStepError {
+		defer replayed.Stop()
+
+		for i, want := range r.Events {
+			if len(steps) != 0 && steps[0].first == i {
+				time.Sleep(time.Until(start.Add(want.Offset)))
+				if err := l.replayStep(steps[0], &wg, errs); err != nil {
+					return err
+				}
+				steps = steps[1:]
+			}
+
+			got, ok := l.replayedEvent(replayed, i, start.Add(want.Offset+tolerance))
+			if !ok {
+				return newError(
+					"replay", want.Message, "missing event",
+					"Expected replay of %v %v at %v", want.Op, want.Message, want.Offset)
+			}
+			if got.Op != want.Op || got.Message != want.Message {
+				return newError(
+					"replay", want.Message, "event mismatch",
+					"Expected replay of %v %v, actual %v %v", want.Op, want.Message, got.Op, got.Message)
+			}
+			if d := got.Offset - want.Offset; d < -tolerance || d > tolerance {
+				return newError(
+					"replay", want.Message, "offset mismatch",
+					"Expected replay of %v %v at %v, actual %v", want.Op, want.Message, want.Offset, got.Offset)
+			}
+			if d := got.Duration - want.Duration; d < -tolerance || d > tolerance {
+				return newError(
+					"replay", want.Message, "duration mismatch",
+					"Expected replay of %v %v after %v, actual %v", want.Op, want.Message, want.Duration, got.Duration)
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		l.Cancel("replay failed")
+	}
+
+	wg.Wait()
+	close(errs)
+
+	if err != nil {
+		l.fail(err)
+		return
+	}
+	// Timeouts were verified along with the other events.
+	for err := range errs {
+		if err.Reason != "timeout" {
+			l.fail(err)
+			return
+		}
+	}
+}
+
+// replaySteps returns the operations started by events. The Wait operations
+// for several messages are recorded as consecutive OpWait events from the
+// same goroutine and call.
+func replaySteps(events []RecordedEvent) ([]replayStep, *LockStepError) {
+	var steps []replayStep
+	for i, e := range events {
+		if e.Op != OpEmit && e.Op != OpWait {
+			continue
+		}
+		if n := len(steps); n != 0 && e.Op == OpWait {
+			if prev, last := events[i-1], &steps[n-1]; last.first+len(last.ms) == i &&
+				prev.Op == OpWait && prev.Goroutine == e.Goroutine && prev.Call == e.Call {
+				last.ms = append(last.ms, e.Message)
+				continue
+			}
+		}
+
+		if !replayable(e.Op, e.Call) {
+			return nil, newError(
+				"replay", e.Message, "unsupported",
+				"Cannot replay %v of %v", e.Call, e.Message)
+		}
+		steps = append(steps, replayStep{first: i, call: e.Call, op: e.Op, ms: []string{e.Message}})
+	}
+	return steps, nil
+}
+
+// replayable returns true if Replay supports the operation op started by the
+// LockStep method call.
+func replayable(op Op, call string) bool {
+	switch call {
+	case "", "Emit", "EmitCtx", "EmitWithin", "EmitE", "EmitValue", "EmitAfter", "EmitN",
+		"Broadcast", "Once", "TryEmit", "BufferedEmit":
+		return op == OpEmit
+	case "Wait", "WaitCtx", "WaitWithin", "WaitE", "WaitValue", "WaitAny",
+		"TryWait", "WaitPrefix", "WaitGlob":
+		return op == OpWait
+	}
+	return false
+}
+
+// replayStep starts the operation s. Operations that block are started from
+// their own goroutine, tracked by wg, which sends their failure to errs.
+func (l *LockStep) replayStep(s replayStep, wg *sync.WaitGroup, errs chan<- *LockStepError) *LockStepError {
+	l.t.Helper()
+
+	m := s.ms[0]
+	var op func() *LockStepError
+	switch s.call {
+	case "Once":
+		l.Once(m)
+	case "TryEmit":
+		l.TryEmit(m)
+	case "BufferedEmit":
+		l.BufferedEmit(m)
+	case "TryWait":
+		l.TryWait(m)
+	case "Broadcast":
+		op = func() *LockStepError {
+			return l.broadcast(m)
+		}
+	case "WaitAny":
+		op = func() *LockStepError {
+			_, err := l.waitAny(s.ms)
+			return err
+		}
+	case "WaitPrefix":
+		prefix := strings.TrimSuffix(strings.TrimPrefix(m, "prefix "), "*")
+		op = func() *LockStepError {
+			_, err := l.awaitPattern(m, func(e string) bool {
+				return strings.HasPrefix(e, prefix)
+			})
+			return err
+		}
+	case "WaitGlob":
+		pattern := strings.TrimPrefix(m, "glob ")
+		op = func() *LockStepError {
+			_, err := l.awaitPattern(m, func(e string) bool {
+				ok, _ := path.Match(pattern, e)
+				return ok
+			})
+			return err
+		}
+	default:
+		if s.op == OpEmit {
+			op = func() *LockStepError {
+				return l.emit(context.Background(), m, nil, l.opTimeout(m))
+			}
+		} else {
+			op = func() *LockStepError {
+				return l.wait(context.Background(), s.ms, l.opTimeout(s.ms...))
+			}
+		}
+	}
+	if op == nil {
+		return nil
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := op(); err != nil {
+			errs <- err
+		}
+	}()
+	return nil
+}
+
+// replayedEvent waits until r has the event at index i, and returns it. ok is
+// false if it does not by deadline.
+func (l *LockStep) replayedEvent(r *Recording, i int, deadline time.Time) (e RecordedEvent, ok bool) {
+	for {
+		l.mu.RLock()
+		if i < len(r.Events) {
+			e = r.Events[i]
+		}
+		n := len(r.Events)
+		l.mu.RUnlock()
+
+		if i < n {
+			return e, true
+		}
+		if time.Now().After(deadline) {
+			return e, false
+		}
+		time.Sleep(replayPollInterval)
+	}
+}
@@ -0,0 +1,151 @@
+package lockstep_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+// replayFailure replays rec with a new LockStep, and returns the first failure
+// reported.
+func replayFailure(t *testing.T, rec *lockstep.Recording) string {
+	var failure string
+	ls := lockstep.New(t)
+	ls.SetFailureHandler(func(format string, args ...any) {
+		if failure == "" {
+			failure = fmt.Sprintf(format, args...)
+		}
+	})
+	ls.Replay(rec)
+	return failure
+}
+
+func TestLockStep_RecordReplay(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	rec := ls.Record()
+	go func() {
+		ls.Emit("x")
+		ls.Emit("y")
+	}()
+	ls.Wait("x")
+	ls.Wait("y")
+	rec.Stop()
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replay lockstep.Recording
+	if err := json.Unmarshal(data, &replay); err != nil {
+		t.Fatal(err)
+	}
+	expectEqual(t, len(rec.Events), len(replay.Events))
+	for _, e := range replay.Events {
+		if e.Op == lockstep.OpEmit {
+			expectEqual(t, "Emit", e.Call)
+		}
+	}
+
+	ls2 := lockstep.New(t)
+	ls2.Replay(&replay)
+	ls2.AssertOrder("x", "y")
+}
+
+func TestLockStep_RecordReplay_Operations(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	rec := ls.Record()
+
+	ls.Once("ready")
+	ls.Wait("ready")
+
+	go ls.Emit("job-1")
+	expectEqual(t, "job-1", ls.WaitPrefix("job-"))
+
+	go ls.Emit("z")
+	for {
+		if _, ok := ls.TryWait("z"); ok {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	go ls.Emit("b")
+	expectEqual(t, "b", ls.WaitAny("a", "b"))
+
+	go ls.Broadcast("reload")
+	ls.Wait("reload")
+	rec.Stop()
+
+	lockstep.New(t).Replay(rec)
+}
+
+func TestLockStep_Replay_Timing(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	rec := ls.Record()
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+	rec.Stop()
+
+	// The replay reproduces the delay of the Emit operation.
+	start := time.Now()
+	lockstep.New(t).Replay(rec)
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Fatalf("Expected replay to take at least 200ms, actual %v", elapsed)
+	}
+}
+
+func TestLockStep_Replay_OrderMismatch(t *testing.T) {
+	t.Parallel()
+
+	rec := &lockstep.Recording{Events: []lockstep.RecordedEvent{
+		{Op: lockstep.OpEmit, Message: "x", Call: "Emit"},
+		{Op: lockstep.OpWait, Message: "x", Call: "Wait"},
+		{Op: lockstep.OpMatch, Message: "y"},
+	}}
+
+	err := replayFailure(t, rec)
+	if !strings.Contains(err, "Expected replay of match y, actual match x") {
+		t.Fatalf("Unexpected failure: %v", err)
+	}
+}
+
+func TestLockStep_Replay_DurationMismatch(t *testing.T) {
+	t.Parallel()
+
+	rec := &lockstep.Recording{Events: []lockstep.RecordedEvent{
+		{Op: lockstep.OpWait, Message: "x", Call: "Wait"},
+		{Op: lockstep.OpEmit, Message: "x", Call: "Emit"},
+		{Op: lockstep.OpMatch, Message: "x", Duration: 500 * time.Millisecond},
+	}}
+
+	err := replayFailure(t, rec)
+	if !strings.Contains(err, "Expected replay of match x after 500ms") {
+		t.Fatalf("Unexpected failure: %v", err)
+	}
+}
+
+func TestLockStep_Replay_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+	rec := ls.Record()
+	go ls.Emit("x")
+	ls.WaitFunc(func(m string) bool { return m == "x" })
+	rec.Stop()
+
+	err := replayFailure(t, rec)
+	expectEqual(t, "Cannot replay WaitFunc of func", err)
+}
@@ -0,0 +1,116 @@
+package lockstep
+
+import (
+	"context"
+	"time"
+)
+
+// rendezvous is a Rendezvous operation waiting for its counterpart.
+type rendezvous struct {
+	start time.Time
+	done  bool
+
+	// sent and received are the values sent by the first and second
+	// goroutines to arrive. See ExchangeValue.
+	sent     any
+	received any
+}
+
+// Rendezvous synchronizes two goroutines symmetrically: the first goroutine to
+// call Rendezvous(m) blocks until a second goroutine calls Rendezvous(m), and
+// then both return. Unlike Emit and Wait, both sides play the same role.
+//
+//	go func() {
+//		mu.Lock()
+//		ls.Rendezvous("locked")
+//		// ...
+//	}()
+//	ls.Rendezvous("locked")
+//
+// Further calls to Rendezvous(m) start a new rendezvous.
+func (l *LockStep) Rendezvous(m string) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	l.logf("Rendezvous on %v", m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.opTimeout(m))
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.rendezvousWithLock(ctx, m, nil); err != nil {
+		l.fail(err)
+	}
+}
+
+// ExchangeValue is like Rendezvous, but the two goroutines also exchange
+// values: each one sends v and returns the value sent by the other.
+//
+//	go func() {
+//		got := ls.ExchangeValue("handoff", "from-producer")
+//		// got == "from-consumer"
+//	}()
+//	got := ls.ExchangeValue("handoff", "from-consumer")
+//	// got == "from-producer"
+//
+// If the operation fails and the failure mode allows it to return,
+// ExchangeValue returns nil.
+func (l *LockStep) ExchangeValue(m string, v any) any {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	l.logf("Exchanging value on %v", m)
+
+	ctx, cancel := context.WithTimeout(context.Background(), l.opTimeout(m))
+	defer cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	received, err := l.rendezvousWithLock(ctx, m, v)
+	if err != nil {
+		l.fail(err)
+	}
+	return received
+}
+
+// rendezvousWithLock blocks until another goroutine arrives at the rendezvous
+// m. It sends v to the other goroutine and returns the value it sent. l.mu
+// must be held.
+func (l *LockStep) rendezvousWithLock(ctx context.Context, m string, v any) (any, *LockStepError) {
+	l.recordWithLock(OpWait, m, 0)
+
+	if r := l.rendezvous[m]; r != nil {
+		delete(l.rendezvous, m)
+		r.done = true
+		r.received = v
+		l.recordWithLock(OpMatch, m, time.Since(r.start))
+		l.broadcastWithLock()
+		return r.sent, nil
+	}
+
+	r := &rendezvous{start: time.Now(), sent: v}
+	if l.rendezvous == nil {
+		l.rendezvous = make(map[string]*rendezvous)
+	}
+	l.rendezvous[m] = r
+
+	defer l.startSoftTimeoutWithLock(func() string {
+		return "waiting for rendezvous on " + m
+	})()
+
+	for !r.done {
+		if !l.waitWithLock(ctx) {
+			if l.rendezvous[m] == r {
+				delete(l.rendezvous, m)
+			}
+			l.recordTimeoutWithLock(OpWait, m, time.Since(r.start))
+			return nil, l.ctxError(ctx, OpWait, m, "waiting for rendezvous on %v", m)
+		}
+	}
+	return r.received, nil
+}
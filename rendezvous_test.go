@@ -0,0 +1,52 @@
+package lockstep_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_Rendezvous(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	var arrived atomic.Bool
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		arrived.Store(true)
+		ls.Rendezvous("x")
+	}()
+	ls.Rendezvous("x")
+	if !arrived.Load() {
+		t.Fatalf("Rendezvous returned before counterpart arrived")
+	}
+
+	go ls.Rendezvous("y")
+	ls.Rendezvous("y")
+
+	ls.SetTimeout(10 * time.Millisecond)
+	expectFail(t, func() {
+		ls.Rendezvous("z")
+	})
+}
+
+func TestLockStep_ExchangeValue(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	got := make(chan string, 1)
+	go func() {
+		got <- ls.ExchangeValue("handoff", "a").(string)
+	}()
+	expectEqual(t, "a", ls.ExchangeValue("handoff", "b").(string))
+	expectEqual(t, "b", <-got)
+
+	ls.SetTimeout(10 * time.Millisecond)
+	expectFail(t, func() {
+		ls.ExchangeValue("handoff", 1)
+	})
+}
@@ -0,0 +1,98 @@
+package lockstep
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// RunConcurrent runs fn in n goroutines, passing each one its id, from 0 to
+// n-1. The goroutines are all started before any of them runs fn, so that
+// they run as concurrently as possible. RunConcurrent returns once all of them
+// have returned. A panic in any of the goroutines is reported as a failure
+// from the calling goroutine.
+//
+//	ls.RunConcurrent(3, func(id int) {
+//		ls.Emit(fmt.Sprintf("done-%d", id))
+//	})
+func (l *LockStep) RunConcurrent(n int, fn func(id int)) {
+	l.t.Helper()
+
+	var started sync.WaitGroup
+	start := make(chan struct{})
+	p := l.runGoroutines(n, func(id int) {
+		started.Done()
+		<-start
+		fn(id)
+	}, &started)
+
+	started.Wait()
+	close(start)
+
+	l.checkPanic(p())
+}
+
+// Coordinate runs each of fns in its own goroutine, and returns once all of
+// them have returned. A panic in any of them is reported as a failure from the
+// calling goroutine.
+//
+//	ls.Coordinate(
+//		func() { ls.Emit("request") },
+//		func() { ls.Wait("request") },
+//	)
+func (l *LockStep) Coordinate(fns ...func()) {
+	l.t.Helper()
+
+	p := l.runGoroutines(len(fns), func(id int) {
+		fns[id]()
+	}, nil)
+	l.checkPanic(p())
+}
+
+// runGoroutines runs fn in n goroutines. If started is not nil, it is
+// incremented by n before starting them. The returned function waits for all
+// the goroutines to return, and returns the description of the first panic,
+// if any.
+func (l *LockStep) runGoroutines(n int, fn func(id int), started *sync.WaitGroup) func() string {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		panicked string
+	)
+
+	wg.Add(n)
+	l.trackGoroutines(n)
+	if started != nil {
+		started.Add(n)
+	}
+	for i := 0; i < n; i++ {
+		go func(id int) {
+			defer wg.Done()
+			defer l.startTrackedGoroutine()()
+			defer func() {
+				if r := recover(); r != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					if panicked == "" {
+						panicked = fmt.Sprintf("goroutine %v: %v\n%s", id, r, debug.Stack())
+					}
+				}
+			}()
+			fn(id)
+		}(i)
+	}
+
+	return func() string {
+		wg.Wait()
+		return panicked
+	}
+}
+
+// checkPanic fails if panicked describes a panic.
+func (l *LockStep) checkPanic(panicked string) {
+	l.t.Helper()
+
+	if panicked != "" {
+		l.fail(newError("run", "", "panic", "Panic in %v", panicked))
+	}
+}
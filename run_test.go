@@ -0,0 +1,63 @@
+package lockstep_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_RunConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			ls.Wait(fmt.Sprintf("done-%d", i))
+		}
+	}()
+
+	var count atomic.Int32
+	ls.RunConcurrent(3, func(id int) {
+		ls.Emit(fmt.Sprintf("done-%d", id))
+		count.Add(1)
+	})
+	expectEqual(t, 3, count.Load())
+}
+
+func TestLockStep_RunConcurrent_Panic(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	expectFail(t, func() {
+		ls.RunConcurrent(2, func(id int) {
+			if id == 1 {
+				panic("boom")
+			}
+		})
+	})
+}
+
+func TestLockStep_Coordinate(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(time.Second))
+
+	var done atomic.Bool
+	ls.Coordinate(
+		func() { ls.Emit("x") },
+		func() {
+			ls.Wait("x")
+			done.Store(true)
+		},
+	)
+	expectEqual(t, true, done.Load())
+
+	expectFail(t, func() {
+		ls.Coordinate(func() {}, func() { panic("boom") })
+	})
+}
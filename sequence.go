@@ -0,0 +1,62 @@
+package lockstep
+
+import "sync"
+
+// Sequence waits for a fixed list of messages, one at a time, in order. Create
+// one using [LockStep.NewSequence].
+type Sequence struct {
+	l  *LockStep
+	ms []string
+
+	mu   sync.Mutex
+	next int
+}
+
+// NewSequence creates a Sequence for the messages ms.
+//
+//	s := ls.NewSequence("connect", "authenticate", "authorize", "ready")
+//	for i := 0; i < 4; i++ {
+//		s.Next()
+//	}
+//
+// This is equivalent to, but more readable than, calling Wait for each
+// message in turn.
+func (l *LockStep) NewSequence(ms ...string) *Sequence {
+	return &Sequence{
+		l:  l,
+		ms: ms,
+	}
+}
+
+// Next waits for the next message in the sequence and returns it. Calling Next
+// after all the messages have been received fails.
+func (s *Sequence) Next() string {
+	s.l.t.Helper()
+
+	s.mu.Lock()
+	i := s.next
+	if i < len(s.ms) {
+		s.next++
+	}
+	s.mu.Unlock()
+
+	if i == len(s.ms) {
+		s.l.fail(newError(
+			"next", "", "sequence exhausted",
+			"Sequence exhausted: all %v messages were received", len(s.ms)))
+		return ""
+	}
+
+	m := s.ms[i]
+	s.l.Wait(m)
+	return m
+}
+
+// Restart makes the next call to Next wait for the first message in the
+// sequence again, e.g. to test a retry.
+func (s *Sequence) Restart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next = 0
+}
@@ -0,0 +1,30 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestSequence(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+	s := ls.NewSequence("connect", "ready")
+
+	go func() {
+		ls.Emit("connect")
+		ls.Emit("ready")
+		ls.Emit("connect")
+	}()
+
+	expectEqual(t, "connect", s.Next())
+	expectEqual(t, "ready", s.Next())
+	expectFail(t, func() {
+		s.Next()
+	})
+
+	s.Restart()
+	expectEqual(t, "connect", s.Next())
+}
@@ -0,0 +1,61 @@
+package lockstep
+
+import (
+	"slices"
+	"strings"
+)
+
+// maxStackDump is the maximum length of the output of DumpGoroutineStacks.
+const maxStackDump = 64 << 10
+
+// truncatedStacks marks the output of DumpGoroutineStacks as truncated.
+const truncatedStacks = "  ... (truncated)\n"
+
+// DumpGoroutineStacks returns the stacks of all the goroutines, except those
+// internal to LockStep, as reported by runtime.Stack. The stacks are indented
+// and separated by blank lines, and the output is truncated to 64KB. The stack
+// of the calling goroutine comes first, followed by those of the goroutines
+// in LockStep operations, so that they survive the truncation.
+//
+// Timeout failures reported with t.Fatalf or t.Errorf (see [FailureMode])
+// include the output of DumpGoroutineStacks, to help find the goroutine that
+// should have emitted the message that timed out.
+func DumpGoroutineStacks() string {
+	stacks := goroutineStacks()
+	// The first stack is the one of the calling goroutine.
+	slices.SortStableFunc(stacks[1:], func(a, b string) int {
+		return compareBool(!strings.Contains(a, packagePrefix), !strings.Contains(b, packagePrefix))
+	})
+
+	var b strings.Builder
+	for _, stack := range stacks {
+		if isInternalGoroutine(stack) {
+			continue
+		}
+		if b.Len() != 0 {
+			b.WriteByte('\n')
+		}
+		for _, line := range strings.Split(stack, "\n") {
+			if b.Len()+len(line)+3+len(truncatedStacks) > maxStackDump {
+				b.WriteString(truncatedStacks)
+				return b.String()
+			}
+			b.WriteString("  ")
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// compareBool orders false before true.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
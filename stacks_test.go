@@ -0,0 +1,59 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestDumpGoroutineStacks(t *testing.T) {
+	t.Parallel()
+
+	stacks := lockstep.DumpGoroutineStacks()
+	if !strings.HasPrefix(stacks, "  goroutine ") {
+		t.Fatalf("Expected indented stacks, actual: %v", stacks)
+	}
+	// The stack of the calling goroutine comes first.
+	first, _, _ := strings.Cut(stacks, "\n\n")
+	if !strings.Contains(first, "TestDumpGoroutineStacks(") {
+		t.Fatalf("Expected stack of the test goroutine first: %v", stacks)
+	}
+	if len(stacks) > 64<<10 {
+		t.Fatalf("Expected at most 64KB, actual %v", len(stacks))
+	}
+}
+
+// TestDumpGoroutineStacks_Blocked is not parallel so that no goroutines of
+// other tests are in LockStep operations.
+func TestDumpGoroutineStacks_Blocked(t *testing.T) {
+	ls := lockstep.New(t)
+	done := make(chan struct{})
+	defer close(done)
+
+	// Started first, but it is not in a LockStep operation.
+	go blockOnChan(done)
+	go blockInWait(ls)
+	defer ls.Emit("x")
+	for ls.PendingCount() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The goroutine blocked in a LockStep operation comes before the others,
+	// which may be truncated, e.g. by the paused parallel tests.
+	stacks := lockstep.DumpGoroutineStacks()
+	wait := strings.Index(stacks, "lockstep_test.blockInWait(")
+	ch := strings.Index(stacks, "lockstep_test.blockOnChan(")
+	if wait < 0 || (ch >= 0 && wait > ch) {
+		t.Fatalf("Expected blocked Wait before other goroutines: %v", stacks)
+	}
+}
+
+func blockInWait(ls *lockstep.LockStep) {
+	ls.Wait("x")
+}
+
+func blockOnChan(done chan struct{}) {
+	<-done
+}
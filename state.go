@@ -0,0 +1,71 @@
+package lockstep
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DumpState returns a human-readable, multi-line description of the current
+// state of the LockStep instance: the Wait and Emit operations in progress,
+// and the number of matches so far. It is safe to call at any time, e.g. from
+// a t.Cleanup function.
+func (l *LockStep) DumpState() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var b strings.Builder
+	name := ""
+	if l.name != "" {
+		name = " [" + l.name + "]"
+	}
+	fmt.Fprintf(&b, "LockStep%v state at %v\n", name, time.Now().Format("15:04:05.000000"))
+
+	waiters := make(map[*waiter]bool)
+	for _, w := range l.waiting {
+		waiters[w] = true
+	}
+	for _, w := range l.patterns {
+		waiters[w] = true
+	}
+	for _, q := range l.waitQueue {
+		for _, w := range q {
+			waiters[w] = true
+		}
+	}
+	waits := make([]string, 0, len(waiters))
+	for w := range waiters {
+		waits = append(waits, w.String())
+	}
+	slices.Sort(waits)
+	fmt.Fprintf(&b, "Waiting for:%v\n", describeOps(waits))
+
+	emits := make([]string, 0, len(l.emitting))
+	for _, m := range slices.Sorted(maps.Keys(l.emitting)) {
+		if n := l.emitting[m]; n > 1 {
+			m = fmt.Sprintf("%v (x%v)", m, n)
+		}
+		emits = append(emits, m)
+	}
+	fmt.Fprintf(&b, "Emitting:%v\n", describeOps(emits))
+
+	matches := 0
+	for _, e := range l.history {
+		if e.Op == OpMatch {
+			matches++
+		}
+	}
+	fmt.Fprintf(&b, "Matched: %v\n", matches)
+
+	return b.String()
+}
+
+// describeOps formats a list of operations, one per line.
+func describeOps(ops []string) string {
+	if len(ops) == 0 {
+		return " (none)"
+	}
+	return "\n  " + strings.Join(ops, "\n  ")
+}
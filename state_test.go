@@ -0,0 +1,52 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_DumpState(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(t)
+
+	go func() {
+		ls.Emit("a")
+	}()
+	ls.Wait("a")
+
+	go func() {
+		ls.Wait("x", "y")
+	}()
+	go func() {
+		ls.WaitAny("z", "w")
+	}()
+	go func() {
+		ls.Emit("e")
+	}()
+	for {
+		s := ls.DumpState()
+		if strings.Contains(s, "Emitting:\n  e\n") && ls.PendingCount() == 4 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s := ls.DumpState()
+	_, s, _ = strings.Cut(s, "\n")
+	expectEqual(t, ""+
+		"Waiting for:\n"+
+		"  any of w, z\n"+
+		"  x, y\n"+
+		"Emitting:\n"+
+		"  e\n"+
+		"Matched: 1\n", s)
+
+	ls.Emit("x")
+	ls.Emit("y")
+	ls.Emit("z")
+	ls.Wait("e")
+}
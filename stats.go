@@ -0,0 +1,85 @@
+package lockstep
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds aggregate counts and timings of the operations of a [LockStep]
+// instance. See [LockStep.Statistics].
+type Stats struct {
+	EmitCount    int64
+	WaitCount    int64
+	MatchCount   int64
+	TimeoutCount int64
+
+	// TotalMatchLatency, MaxMatchLatency and MinMatchLatency aggregate the
+	// Duration of OpMatch events. They are zero if there were no matches.
+	TotalMatchLatency time.Duration
+	MaxMatchLatency   time.Duration
+	MinMatchLatency   time.Duration
+}
+
+// stats accumulates Stats. Its fields are updated atomically so that a
+// snapshot can be taken without holding LockStep.mu.
+type stats struct {
+	emits    atomic.Int64
+	waits    atomic.Int64
+	matches  atomic.Int64
+	timeouts atomic.Int64
+
+	totalLatency atomic.Int64
+	maxLatency   atomic.Int64
+	minLatency   atomic.Int64
+}
+
+// Statistics returns a snapshot of the aggregate statistics of the LockStep
+// instance. Counters are reset by [LockStep.Reset].
+func (l *LockStep) Statistics() Stats {
+	return Stats{
+		EmitCount:         l.stats.emits.Load(),
+		WaitCount:         l.stats.waits.Load(),
+		MatchCount:        l.stats.matches.Load(),
+		TimeoutCount:      l.stats.timeouts.Load(),
+		TotalMatchLatency: time.Duration(l.stats.totalLatency.Load()),
+		MaxMatchLatency:   time.Duration(l.stats.maxLatency.Load()),
+		MinMatchLatency:   time.Duration(l.stats.minLatency.Load()),
+	}
+}
+
+func (s *stats) add(e Event) {
+	switch e.Op {
+	case OpEmit:
+		s.emits.Add(1)
+	case OpWait:
+		s.waits.Add(1)
+	case OpTimeout:
+		s.timeouts.Add(1)
+	case OpMatch:
+		d := int64(e.Duration)
+		first := s.matches.Add(1) == 1
+		s.totalLatency.Add(d)
+		for {
+			cur := s.maxLatency.Load()
+			if (!first && cur >= d) || s.maxLatency.CompareAndSwap(cur, d) {
+				break
+			}
+		}
+		for {
+			cur := s.minLatency.Load()
+			if (!first && cur <= d) || s.minLatency.CompareAndSwap(cur, d) {
+				break
+			}
+		}
+	}
+}
+
+func (s *stats) reset() {
+	s.emits.Store(0)
+	s.waits.Store(0)
+	s.matches.Store(0)
+	s.timeouts.Store(0)
+	s.totalLatency.Store(0)
+	s.maxLatency.Store(0)
+	s.minLatency.Store(0)
+}
@@ -0,0 +1,44 @@
+package lockstep_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_Statistics(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	expectEqual(t, lockstep.Stats{}, ls.Statistics())
+
+	go func() {
+		ls.Emit("x")
+		time.Sleep(50 * time.Millisecond)
+		ls.Emit("y")
+	}()
+	ls.Wait("x", "y")
+	expectFail(t, func() {
+		ls.Emit("z")
+	})
+
+	s := ls.Statistics()
+	expectEqual(t, 3, s.EmitCount)
+	expectEqual(t, 2, s.WaitCount)
+	expectEqual(t, 2, s.MatchCount)
+	expectEqual(t, 1, s.TimeoutCount)
+	if s.MinMatchLatency > s.MaxMatchLatency {
+		t.Fatalf("Min latency %v > max latency %v", s.MinMatchLatency, s.MaxMatchLatency)
+	}
+	if s.MaxMatchLatency < 50*time.Millisecond {
+		t.Fatalf("Unexpected max latency %v", s.MaxMatchLatency)
+	}
+	if s.TotalMatchLatency < s.MaxMatchLatency+s.MinMatchLatency {
+		t.Fatalf("Unexpected total latency %v", s.TotalMatchLatency)
+	}
+
+	ls.Reset()
+	expectEqual(t, lockstep.Stats{}, ls.Statistics())
+}
@@ -0,0 +1,43 @@
+package lockstep
+
+const (
+	// stepAdvance and stepDone are the messages used by StepThrough and
+	// Advance.
+	stepAdvance = "lockstep-step-advance"
+	stepDone    = "lockstep-step-done"
+)
+
+// StepThrough calls fn with each of the steps in turn, but only when the test
+// calls Advance: each call to Advance runs exactly one step, and returns once
+// it is done. This lets the test check invariants at each intermediate state
+// of a long-running operation.
+//
+//	go ls.StepThrough([]string{"load", "transform", "store"}, func(step string) {
+//		pipeline.Run(step)
+//	})
+//	ls.Advance() // load
+//	checkInvariants()
+//	ls.Advance() // transform
+//	checkInvariants()
+//	ls.Advance() // store
+//
+// Only one StepThrough can be in progress at a time.
+func (l *LockStep) StepThrough(steps []string, fn func(step string)) {
+	l.t.Helper()
+
+	for _, step := range steps {
+		l.Wait(stepAdvance)
+		l.logf("Step %v", step)
+		fn(step)
+		l.Emit(stepDone)
+	}
+}
+
+// Advance lets the StepThrough in progress run its next step, and waits for
+// the step to complete.
+func (l *LockStep) Advance() {
+	l.t.Helper()
+
+	l.Emit(stepAdvance)
+	l.Wait(stepDone)
+}
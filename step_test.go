@@ -0,0 +1,43 @@
+package lockstep_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_StepThrough(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	var mu sync.Mutex
+	var done []string
+	steps := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(done)
+	}
+
+	go ls.StepThrough([]string{"a", "b"}, func(step string) {
+		mu.Lock()
+		defer mu.Unlock()
+		done = append(done, step)
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	expectEqual(t, 0, steps())
+
+	ls.Advance()
+	expectEqual(t, 1, steps())
+
+	ls.Advance()
+	expectEqual(t, 2, steps())
+	expectEqual(t, "b", done[1])
+
+	expectFail(t, func() {
+		ls.Advance()
+	})
+}
@@ -0,0 +1,95 @@
+package lockstep
+
+import (
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// StressTest runs fn iterations times with the same LockStep instance,
+// resetting it after each iteration. Each iteration runs with a different
+// GOMAXPROCS, from 1 to the number of CPUs, to vary the scheduling of the
+// goroutines. After each iteration, StressTest waits for all the operations
+// to complete and checks that no goroutines were leaked (see
+// [WithLeakDetection]).
+//
+// An iteration in which an operation times out is counted as a flake instead
+// of failing the test right away: the failed operation returns immediately
+// and the iteration continues. Once all the iterations have run, the flake
+// rate is reported as a failure, and the minimum, maximum and 99th percentile
+// of the Duration of the OpMatch events are logged.
+//
+//	lockstep.StressTest(t, 1000, func(ls *lockstep.LockStep) {
+//		go ls.Emit("x")
+//		ls.Wait("x")
+//	})
+//
+// The LockStep instance is created with opts. For example, [VaryDelay] adds
+// random delays to explore more interleavings.
+//
+// Since GOMAXPROCS and the goroutine count are global, StressTest must not run
+// in parallel with other tests.
+func StressTest(t testing.TB, iterations int, fn func(*LockStep), opts ...Option) {
+	t.Helper()
+
+	var timedOut atomic.Bool
+	l := New(t, opts...)
+	l.OnTimeout(func(string, string, time.Duration) {
+		timedOut.Store(true)
+	})
+	l.SetFailureHandler(func(format string, args ...any) {
+		if !timedOut.Load() {
+			t.Errorf(format, args...)
+		}
+	})
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+
+	var latencies []time.Duration
+	flakes := 0
+	for i := 0; i < iterations; i++ {
+		runtime.GOMAXPROCS(1 + i%runtime.NumCPU())
+		timedOut.Store(false)
+		before := goroutineIDs()
+
+		fn(l)
+
+		if err := l.WaitForIdle(l.opTimeout()); err != nil {
+			t.Errorf("StressTest: iteration %v: %v", i, err)
+			return
+		}
+		l.checkLeaks(before)
+
+		if timedOut.Load() {
+			flakes++
+		}
+		for _, e := range l.History() {
+			if e.Op == OpMatch {
+				latencies = append(latencies, e.Duration)
+			}
+		}
+		l.Reset()
+	}
+
+	if len(latencies) != 0 {
+		slices.Sort(latencies)
+		t.Logf("StressTest: %v iterations, match latency min %v, max %v, p99 %v",
+			iterations, latencies[0], latencies[len(latencies)-1], percentile(latencies, 99))
+	}
+	if flakes != 0 {
+		t.Errorf("StressTest: %v of %v iterations timed out (flake rate %.1f%%)",
+			flakes, iterations, 100*float64(flakes)/float64(iterations))
+	}
+}
+
+// percentile returns the p-th percentile of the sorted, non-empty ds, using
+// the nearest-rank method.
+func percentile(ds []time.Duration, p int) time.Duration {
+	rank := (p*len(ds) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return ds[rank-1]
+}
@@ -0,0 +1,51 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+// The tests below are not parallel because StressTest changes GOMAXPROCS and
+// checks for leaked goroutines.
+
+func TestStressTest(t *testing.T) {
+	r := &Recorder{T: t}
+	n := 0
+	lockstep.StressTest(r, 20, func(ls *lockstep.LockStep) {
+		n++
+		go ls.Emit("x")
+		ls.Wait("x")
+	})
+
+	expectEqual(t, 20, n)
+	expectEqual(t, 0, len(r.Errors()))
+	logs := r.Logs()
+	if len(logs) == 0 || !strings.HasPrefix(logs[len(logs)-1], "StressTest: 20 iterations, match latency min ") {
+		t.Fatalf("Unexpected logs: %q", logs)
+	}
+}
+
+func TestStressTest_Flakes(t *testing.T) {
+	r := &Recorder{T: t}
+	i := 0
+	lockstep.StressTest(r, 4, func(ls *lockstep.LockStep) {
+		ls.SetTimeout(20 * time.Millisecond)
+		if i++; i%2 == 0 {
+			ls.Wait("never")
+		}
+	})
+
+	errs := r.Errors()
+	expectEqual(t, 1, len(errs))
+	expectEqual(t, "StressTest: 2 of 4 iterations timed out (flake rate 50.0%)", errs[0])
+}
+
+func TestStressTest_Options(t *testing.T) {
+	lockstep.StressTest(t, 10, func(ls *lockstep.LockStep) {
+		go ls.Emit("x")
+		ls.Wait("x")
+	}, lockstep.VaryDelay(0, time.Millisecond), lockstep.WithSeed(1))
+}
@@ -0,0 +1,108 @@
+package lockstep
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// timelineLabelWidth and timelineWidth are chosen so that timeline rows
+	// fit in 120 columns.
+	timelineLabelWidth = 24
+	timelineWidth      = 90
+)
+
+// timelineMarks maps each Op to the character used to represent it in the
+// timeline. When several events fall into the same column, the one with the
+// highest priority (latest in timelineOrder) is shown.
+var timelineMarks = map[Op]byte{
+	OpWait:    'W',
+	OpEmit:    'E',
+	OpMatch:   'M',
+	OpTimeout: 'T',
+}
+
+var timelineOrder = []Op{OpWait, OpEmit, OpMatch, OpTimeout}
+
+// PrintTimeline logs, using t.Logf, an ASCII chart of the events in the
+// history of the LockStep instance. Each message is shown in its own row,
+// and time advances from left to right starting at the earliest event.
+// PrintTimeline is called automatically during test cleanup if the test
+// failed.
+func (l *LockStep) PrintTimeline() {
+	l.t.Helper()
+
+	events := l.History()
+	if len(events) == 0 {
+		return
+	}
+	l.t.Logf("LockStep timeline:\n%v", timeline(events))
+}
+
+// timeline renders events as an ASCII chart. events must be in chronological
+// order and non-empty.
+func timeline(events []Event) string {
+	start := events[0].Time
+	span := events[len(events)-1].Time.Sub(start)
+
+	column := func(t time.Time) int {
+		if span <= 0 {
+			return 0
+		}
+		c := int(int64(t.Sub(start)) * (timelineWidth - 1) / int64(span))
+		if c >= timelineWidth {
+			c = timelineWidth - 1
+		}
+		return c
+	}
+
+	priority := make(map[byte]int)
+	for i, op := range timelineOrder {
+		priority[timelineMarks[op]] = i + 1
+	}
+
+	var order []string
+	rows := make(map[string][]byte)
+	for _, e := range events {
+		row, ok := rows[e.Message]
+		if !ok {
+			row = []byte(strings.Repeat(".", timelineWidth))
+			rows[e.Message] = row
+			order = append(order, e.Message)
+		}
+		mark, ok := timelineMarks[e.Op]
+		if !ok {
+			continue
+		}
+		c := column(e.Time)
+		if priority[mark] > priority[row[c]] {
+			row[c] = mark
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-*v |%-*v|\n",
+		timelineLabelWidth, "message",
+		timelineWidth, fmt.Sprintf("0 .. %v", span))
+	for _, m := range order {
+		fmt.Fprintf(&b, "%-*v |%s|\n", timelineLabelWidth, timelineLabel(m), rows[m])
+	}
+	b.WriteString("W=wait E=emit M=match T=timeout")
+	return b.String()
+}
+
+// timelineLabel truncates m to fit in the label column, replacing non-ASCII
+// and non-printable characters with '?'.
+func timelineLabel(m string) string {
+	label := []byte(m)
+	for i, c := range label {
+		if c < ' ' || c > '~' {
+			label[i] = '?'
+		}
+	}
+	if len(label) > timelineLabelWidth {
+		label = append(label[:timelineLabelWidth-3], "..."...)
+	}
+	return string(label)
+}
@@ -0,0 +1,50 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_PrintTimeline(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r, lockstep.WithTimeout(50*time.Millisecond),
+		lockstep.WithFailureMode(lockstep.ErrorMode))
+
+	ls.PrintTimeline()
+	expectEqual(t, 0, len(r.Logs()))
+
+	go ls.Emit("a-very-long-message-name-that-needs-truncation")
+	ls.Wait("a-very-long-message-name-that-needs-truncation")
+	ls.Wait("never")
+
+	ls.PrintTimeline()
+	logs := r.Logs()
+	timeline := logs[len(logs)-1]
+
+	lines := strings.Split(timeline, "\n")
+	expectEqual(t, 5, len(lines))
+	for _, line := range lines {
+		if len(line) > 120 {
+			t.Fatalf("Line too long (%v): %q", len(line), line)
+		}
+		for _, c := range line {
+			if c > '~' {
+				t.Fatalf("Non-ASCII character in line: %q", line)
+			}
+		}
+	}
+	if !strings.HasPrefix(lines[2], "a-very-long-message-n... |") {
+		t.Fatalf("Unexpected row: %q", lines[2])
+	}
+	if !strings.Contains(lines[2], "M") {
+		t.Fatalf("Expected match in row: %q", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], "never") || !strings.HasSuffix(lines[3], "T|") {
+		t.Fatalf("Expected timeout at end of row: %q", lines[3])
+	}
+}
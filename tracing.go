@@ -0,0 +1,73 @@
+package lockstep
+
+import (
+	"context"
+	"runtime/pprof"
+	"runtime/trace"
+)
+
+// Tracer creates trace spans for Emit and Wait operations. See [WithTracer].
+type Tracer interface {
+	// Start starts a span named name, with the attribute "lockstep.message"
+	// set to m. ctx is the context of the operation.
+	Start(ctx context.Context, name, m string) Span
+}
+
+// Span is a trace span created by a [Tracer].
+type Span interface {
+	// AddLink links the span to another span created by the same Tracer.
+	AddLink(to Span)
+	// End ends the span.
+	End()
+}
+
+// WithTracer configures the LockStep to trace Emit and Wait operations: each
+// Emit or Wait operation creates a span named "lockstep.emit" or
+// "lockstep.wait", and when they are matched, the span of the Wait operation
+// is linked to the span of the Emit operation. In a tracing system such as
+// Jaeger, this shows the dependencies between the goroutines of a concurrent
+// test. Besides Emit and Wait and their variants that take a context, a
+// timeout or a value, EmitN, Broadcast and Once create "lockstep.emit" spans,
+// and WaitN, WaitAny and CountDown create "lockstep.wait" spans. A single span
+// covers all the messages of EmitN, WaitN and CountDown.
+//
+// To keep LockStep free of dependencies, Tracer is an interface. For
+// OpenTelemetry, use WithOpenTelemetry from the separate module
+// github.com/dcaiafa/lockstep/lockstepotel:
+//
+//	ls := lockstep.New(t, lockstepotel.WithOpenTelemetry(tracer))
+func WithTracer(t Tracer) Option {
+	return func(l *LockStep) {
+		l.tracer = t
+	}
+}
+
+type spanKey struct{}
+
+// contextWithSpan returns a copy of ctx carrying span.
+func contextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// spanFromContext returns the span carried by ctx, or nil.
+func spanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+// traceRegion runs fn in a runtime/trace region named name, if tracing is
+// enabled, e.g. with go test -trace. The message m is logged in the region,
+// and set as the profiler label "lockstep.message" of the goroutine while fn
+// runs.
+func traceRegion(ctx context.Context, name, m string, fn func()) {
+	if !trace.IsEnabled() {
+		fn()
+		return
+	}
+	pprof.Do(ctx, pprof.Labels("lockstep.message", m), func(ctx context.Context) {
+		trace.WithRegion(ctx, name, func() {
+			trace.Log(ctx, "lockstep.message", m)
+			fn()
+		})
+	})
+}
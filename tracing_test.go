@@ -0,0 +1,145 @@
+package lockstep_test
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+	"sync"
+	"testing"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+// fakeTracer is a lockstep.Tracer that records the spans it creates.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+type fakeSpan struct {
+	tracer *fakeTracer
+	name   string
+	m      string
+	links  []*fakeSpan
+	ended  bool
+}
+
+func (t *fakeTracer) Start(_ context.Context, name, m string) lockstep.Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := &fakeSpan{tracer: t, name: name, m: m}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+func (s *fakeSpan) AddLink(to lockstep.Span) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.links = append(s.links, to.(*fakeSpan))
+}
+
+func (s *fakeSpan) End() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.ended = true
+}
+
+func TestWithTracer(t *testing.T) {
+	t.Parallel()
+
+	tracer := &fakeTracer{}
+	ls := lockstep.New(t, lockstep.WithTracer(tracer))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.Emit("x")
+	}()
+	ls.Wait("x")
+	<-done
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+
+	spans := make(map[string]*fakeSpan)
+	for _, s := range tracer.spans {
+		expectEqual(t, "x", s.m)
+		expectEqual(t, true, s.ended)
+		spans[s.name] = s
+	}
+	expectEqual(t, 2, len(tracer.spans))
+	wait, emit := spans["lockstep.wait"], spans["lockstep.emit"]
+	if wait == nil || emit == nil {
+		t.Fatalf("Unexpected spans: %v", spans)
+	}
+	expectEqual(t, 1, len(wait.links))
+	if wait.links[0] != emit {
+		t.Fatalf("Expected wait span to link to emit span")
+	}
+}
+
+// TestTraceRegions is not parallel because only one execution trace can be
+// collected at a time.
+func TestTraceRegions(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("Cannot start trace: %v", err)
+	}
+
+	ls := lockstep.New(t)
+	go ls.Emit("x")
+	ls.Wait("x")
+
+	trace.Stop()
+	for _, s := range []string{"lockstep.emit", "lockstep.wait", "lockstep.message"} {
+		if !bytes.Contains(buf.Bytes(), []byte(s)) {
+			t.Fatalf("Expected %q in trace", s)
+		}
+	}
+}
+
+func TestWithTracer_Operations(t *testing.T) {
+	t.Parallel()
+
+	tracer := &fakeTracer{}
+	ls := lockstep.New(t, lockstep.WithTracer(tracer))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ls.EmitN("x", 2)
+		ls.Broadcast("y")
+	}()
+	ls.WaitN("x", 2)
+	ls.WaitAny("y", "z")
+	<-done
+	ls.Once("z")
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+
+	spans := make(map[string]*fakeSpan)
+	for _, s := range tracer.spans {
+		expectEqual(t, true, s.ended)
+		spans[s.name+" "+s.m] = s
+	}
+	expectEqual(t, 5, len(tracer.spans))
+	for _, c := range []struct{ wait, emit string }{
+		{"x", "x"},
+		{"y, z", "y"},
+	} {
+		wait, emit := spans["lockstep.wait "+c.wait], spans["lockstep.emit "+c.emit]
+		if wait == nil || emit == nil {
+			t.Fatalf("Unexpected spans: %v", spans)
+		}
+		for _, link := range wait.links {
+			if link != emit {
+				t.Fatalf("Expected %v wait span to link to emit span", c.wait)
+			}
+		}
+	}
+	expectEqual(t, 2, len(spans["lockstep.wait x"].links))
+	if spans["lockstep.emit z"] == nil {
+		t.Fatalf("Expected Once span: %v", spans)
+	}
+}
@@ -0,0 +1,73 @@
+package lockstep
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TypedLockStep is a [LockStep] whose messages are values of type T instead of
+// strings. Using a dedicated type (e.g. an iota enum) for messages turns typos
+// in message names into compile errors.
+//
+// Messages are converted to strings using the %v format verb, so distinct
+// values of T must format differently.
+type TypedLockStep[T comparable] struct {
+	l *LockStep
+}
+
+// NewTyped creates a TypedLockStep instance. The provided test context will be
+// used for logging and for timeout failures. It accepts the same options as
+// [New].
+func NewTyped[T comparable](t testing.TB, opts ...Option) *TypedLockStep[T] {
+	return &TypedLockStep[T]{l: New(t, opts...)}
+}
+
+// SetTimeout is like [LockStep.SetTimeout].
+func (l *TypedLockStep[T]) SetTimeout(d time.Duration) {
+	l.l.SetTimeout(d)
+}
+
+// SetVerbose is like [LockStep.SetVerbose].
+func (l *TypedLockStep[T]) SetVerbose(v bool) {
+	l.l.SetVerbose(v)
+}
+
+// Emit is like [LockStep.Emit].
+func (l *TypedLockStep[T]) Emit(v T) {
+	l.l.t.Helper()
+	l.l.Emit(typedKey(v))
+}
+
+// Wait is like [LockStep.Wait].
+func (l *TypedLockStep[T]) Wait(vs ...T) {
+	l.l.t.Helper()
+	l.l.Wait(typedKeys(vs)...)
+}
+
+// WaitAny is like [LockStep.WaitAny]. If the operation fails and the failure
+// mode allows it to return, WaitAny returns the zero value of T.
+func (l *TypedLockStep[T]) WaitAny(vs ...T) T {
+	l.l.t.Helper()
+
+	m := l.l.WaitAny(typedKeys(vs)...)
+	for _, v := range vs {
+		if typedKey(v) == m {
+			return v
+		}
+	}
+	var zero T
+	return zero
+}
+
+func typedKey[T comparable](v T) string {
+	return fmt.Sprintf("%v", v)
+}
+
+func typedKeys[T comparable](vs []T) []string {
+	ms := make([]string, len(vs))
+	for i, v := range vs {
+		ms[i] = typedKey(v)
+	}
+	return ms
+}
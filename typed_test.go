@@ -0,0 +1,92 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+type step int
+
+const (
+	stepStart step = iota
+	stepReady
+	stepDone
+)
+
+func (s step) String() string {
+	switch s {
+	case stepStart:
+		return "start"
+	case stepReady:
+		return "ready"
+	case stepDone:
+		return "done"
+	default:
+		return "unknown"
+	}
+}
+
+func TestTypedLockStep(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.NewTyped[step](t)
+
+	go func() {
+		ls.Wait(stepStart)
+		ls.Emit(stepDone)
+		ls.Emit(stepReady)
+	}()
+
+	ls.Emit(stepStart)
+	ls.Wait(stepReady, stepDone)
+}
+
+func TestTypedLockStep_WaitAny(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.NewTyped[step](t)
+
+	go func() {
+		ls.Emit(stepDone)
+	}()
+
+	expectEqual(t, stepDone, ls.WaitAny(stepReady, stepDone))
+}
+
+func TestTypedLockStep_Timeout(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.NewTyped[step](&PanicFailer{T: t})
+	ls.SetTimeout(100 * time.Millisecond)
+
+	expectFail(t, func() {
+		ls.Wait(stepDone)
+	})
+}
+
+// phase is a message type whose zero value is not a message.
+type phase int
+
+const (
+	phaseReady phase = iota + 1
+	phaseDone
+)
+
+func TestTypedLockStep_WaitAnyTimeout(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.NewTyped[phase](r,
+		lockstep.WithTimeout(100*time.Millisecond),
+		lockstep.WithFailureMode(lockstep.ErrorMode))
+
+	expectEqual(t, phase(0), ls.WaitAny(phaseReady, phaseDone))
+	errs := r.Errors()
+	expectEqual(t, 1, len(errs))
+	if !strings.HasPrefix(errs[0], "Timeout waiting for any of 1, 2") {
+		t.Fatalf("Unexpected error: %v", errs[0])
+	}
+}
@@ -2,6 +2,7 @@ package lockstep_test
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 )
 
@@ -20,6 +21,46 @@ func (f *PanicFailer) Fatalf(msg string, args ...any) {
 	panic(FailError(errMsg))
 }
 
+// Recorder records calls to Error, Errorf and Logf instead of passing them on
+// to the test.
+type Recorder struct {
+	*testing.T
+
+	mu   sync.Mutex
+	errs []string
+	logs []string
+}
+
+func (r *Recorder) Errorf(msg string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, fmt.Sprintf(msg, args...))
+}
+
+func (r *Recorder) Error(args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, fmt.Sprint(args...))
+}
+
+func (r *Recorder) Logf(msg string, args ...any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, fmt.Sprintf(msg, args...))
+}
+
+func (r *Recorder) Errors() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.errs...)
+}
+
+func (r *Recorder) Logs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.logs...)
+}
+
 func expectFail(t *testing.T, f func()) {
 	t.Helper()
 
@@ -0,0 +1,97 @@
+package lockstep
+
+import (
+	"context"
+	"reflect"
+)
+
+// EmitValue is like Emit, but the Emit operation also carries the value v,
+// which is returned by the corresponding WaitValue operation.
+//
+//	go func() {
+//		row := db.Query()
+//		ls.EmitValue("db-row", row)
+//	}()
+//	row := ls.WaitValue("db-row").(Row)
+func (l *LockStep) EmitValue(m string, v any) {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	if err := l.emit(context.Background(), m, v, l.opTimeout(m)); err != nil {
+		l.fail(err)
+	}
+}
+
+// WaitValue is like Wait for the single message m, but it returns the value
+// carried by the corresponding Emit operation. It returns nil if m was emitted
+// without a value, e.g. using Emit, or if the operation fails and the failure
+// mode allows it to return.
+func (l *LockStep) WaitValue(m string) any {
+	l.t.Helper()
+
+	m = l.qualify(m)
+
+	v, err := l.waitValue(m)
+	if err != nil {
+		l.fail(err)
+		return nil
+	}
+	return v
+}
+
+// waitValue is like WaitValue, but it returns failures.
+func (l *LockStep) waitValue(m string) (any, *LockStepError) {
+	l.t.Helper()
+
+	l.logf("Waiting for value of %v", m)
+
+	w := newWaiter(false)
+	if l.tracer != nil {
+		w.span = l.tracer.Start(context.Background(), "lockstep.wait", m)
+		defer w.span.End()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.awaitWithLock(context.Background(), w, []string{m}, 1, l.opTimeout(m)); err != nil {
+		return nil, err
+	}
+	return w.values[m], nil
+}
+
+// Emit is a type-safe version of [LockStep.EmitValue].
+//
+//	lockstep.Emit(ls, "db-row", row)
+func Emit[T any](l *LockStep, m string, v T) {
+	l.t.Helper()
+	l.EmitValue(m, v)
+}
+
+// Wait is a type-safe version of [LockStep.WaitValue]. It fails if the value
+// carried by the corresponding Emit operation is not of type T.
+//
+//	row := lockstep.Wait[Row](ls, "db-row")
+func Wait[T any](l *LockStep, m string) T {
+	l.t.Helper()
+
+	var zero T
+	v, err := l.waitValue(l.qualify(m))
+	if err != nil {
+		l.fail(err)
+		return zero
+	}
+	if tv, ok := v.(T); ok {
+		return tv
+	}
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if v == nil && typ.Kind() == reflect.Interface {
+		return zero
+	}
+	l.fail(newError(
+		OpWait, m, "type mismatch",
+		"Type mismatch waiting for %v: expected %v, actual %T", m, typ, v))
+	return zero
+}
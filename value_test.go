@@ -0,0 +1,71 @@
+package lockstep_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dcaiafa/lockstep"
+)
+
+func TestLockStep_EmitValue(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	go ls.EmitValue("row", 42)
+	expectEqual(t, 42, ls.WaitValue("row").(int))
+
+	go ls.Emit("row")
+	if v := ls.WaitValue("row"); v != nil {
+		t.Fatalf("Expected nil value, got %v", v)
+	}
+
+	go ls.EmitValue("row", "ignored")
+	ls.Wait("row")
+
+	expectFail(t, func() {
+		ls.WaitValue("row")
+	})
+}
+
+func TestEmitWait_Generic(t *testing.T) {
+	t.Parallel()
+
+	ls := lockstep.New(&PanicFailer{T: t}, lockstep.WithTimeout(100*time.Millisecond))
+
+	go lockstep.Emit(ls, "count", 42)
+	expectEqual(t, 42, lockstep.Wait[int](ls, "count"))
+
+	go lockstep.Emit[error](ls, "err", nil)
+	if err := lockstep.Wait[error](ls, "err"); err != nil {
+		t.Fatalf("Expected nil error, got %v", err)
+	}
+
+	go lockstep.Emit(ls, "count", "42")
+	expectFail(t, func() {
+		lockstep.Wait[int](ls, "count")
+	})
+
+	go ls.Emit("count")
+	expectFail(t, func() {
+		lockstep.Wait[int](ls, "count")
+	})
+}
+
+func TestEmitWait_GenericTimeout(t *testing.T) {
+	t.Parallel()
+
+	r := &Recorder{T: t}
+	ls := lockstep.New(r,
+		lockstep.WithTimeout(100*time.Millisecond),
+		lockstep.WithFailureMode(lockstep.ErrorMode))
+
+	expectEqual(t, 0, lockstep.Wait[int](ls, "count"))
+	// Only the timeout is reported, not a type mismatch.
+	errs := r.Errors()
+	expectEqual(t, 1, len(errs))
+	if !strings.HasPrefix(errs[0], "Timeout waiting for count") {
+		t.Fatalf("Unexpected error: %v", errs[0])
+	}
+}